@@ -2,28 +2,27 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/Koyo-os/answer-service/internal/api"
 	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/internal/database"
 	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/formclient"
 	"github.com/Koyo-os/answer-service/internal/repository"
 	"github.com/Koyo-os/answer-service/internal/service"
+	"github.com/Koyo-os/answer-service/internal/warmer"
 	"github.com/Koyo-os/answer-service/pkg/closer"
 	"github.com/Koyo-os/answer-service/pkg/health"
 	"github.com/Koyo-os/answer-service/pkg/logger"
 	"github.com/Koyo-os/answer-service/pkg/retrier"
 	"github.com/Koyo-os/answer-service/pkg/transport/casher"
-	"github.com/Koyo-os/answer-service/pkg/transport/consumer"
 	"github.com/Koyo-os/answer-service/pkg/transport/listener"
-	"github.com/Koyo-os/answer-service/pkg/transport/publisher"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
@@ -50,18 +49,19 @@ func main() {
 
 	cfg := config.NewConfig()
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-	)
+	dsn, err := database.DSN(cfg.Database.Dialect)
+	if err != nil {
+		logger.Error("invalid database config", zap.Error(err))
+
+		return
+	}
 
-	logger.Info("connecting to mariadb...", zap.String("dsn", dsn))
+	logger.Info("connecting to database...",
+		zap.String("dialect", cfg.Database.Dialect),
+		zap.String("dsn", dsn))
 
 	db, err := retrier.Connect(10, 10, func() (*gorm.DB, error) {
-		return gorm.Open(mysql.Open(dsn))
+		return database.Open(cfg.Database.Dialect, dsn)
 	})
 	if err != nil {
 		logger.Error("error initialyze database",
@@ -71,40 +71,26 @@ func main() {
 		return
 	}
 
-	logger.Info("connected to mariadb", zap.String("dsn", dsn))
+	logger.Info("connected to database",
+		zap.String("dialect", cfg.Database.Dialect),
+		zap.String("dsn", dsn))
 
-	if err := db.AutoMigrate(&entity.Answer{}, &entity.Element{}); err != nil {
+	if err := database.Migrate(db); err != nil {
 		logger.Error("failed to migrate database", zap.Error(err))
 		return
 	}
 
 	repo := repository.NewRepository(db, logger)
 
-	rabbitmqConns, err := retrier.MultiConnects(2, func() (*amqp.Connection, error) {
-		return amqp.Dial(cfg.Urls["rabbitmq"])
-	}, &retrier.RetrierOpts{Count: 3, Interval: 5})
+	publisher, consumers, err := newTransport(cfg, logger)
 	if err != nil {
-		logger.Error("error connect to rabbitmq",
-			zap.String("url", cfg.Urls["rabbitmq"]),
+		logger.Error("error initialize transport",
+			zap.String("driver", cfg.Transport.Driver),
 			zap.Error(err))
 
 		return
 	}
 
-	publisher, err := publisher.Init(cfg, logger, rabbitmqConns[0])
-	if err != nil {
-		logger.Error("error initialize publisher", zap.Error(err))
-
-		return
-	}
-
-	consumer, err := consumer.Init(cfg, logger, rabbitmqConns[1])
-	if err != nil {
-		logger.Error("error initialize consumer", zap.Error(err))
-
-		return
-	}
-
 	redisConn, err := retrier.Connect(3, 5, func() (*redis.Client, error) {
 		client := redis.NewClient(&redis.Options{
 			Addr:     cfg.Urls["redis"],
@@ -120,23 +106,63 @@ func main() {
 		return
 	}
 
-	casher := casher.Init(redisConn, logger)
+	casher, err := casher.Init(redisConn, logger, cfg)
+	if err != nil {
+		logger.Error("error initialize casher", zap.Error(err))
+
+		return
+	}
+
+	core := service.NewService(casher, publisher, repo, repo, 10 * time.Second)
+	core.SetRequireKnownForms(cfg.Forms.RequireKnown)
 
-	core := service.NewService(casher, publisher, repo, 10 * time.Second)
+	if cfg.Forms.ServiceURL != "" {
+		core.SetFormProvider(formclient.New(cfg.Forms.ServiceURL, cfg.Forms.Timeout))
+	}
 
 	listener := listener.NewListener(logger, core, eventChan)
 
+	// Only transports with a reply destination (AMQP ReplyTo) support request/reply
+	if replier, ok := publisher.(interface {
+		Reply(string, string, any) error
+	}); ok {
+		listener.SetReplier(replier)
+	}
+
+	warmer := warmer.NewWarmer(repo, casher, logger, cfg)
+
 	logger.Info("service ready to start!")
 
 	healther := health.NewHealthChecker(publisher, casher)
+	healther.AddStats("cache", func() any { return casher.Stats() })
 
 	go listener.Run(context.Background())
-	go consumer.ConsumeMessages(eventChan)
+	for _, consumer := range consumers {
+		go consumer.ConsumeMessages(eventChan)
+	}
 	go healther.RunServer(":8080")
+	go warmer.Run(context.Background())
+
+	closers := []closer.Closer{publisher, casher}
+
+	// Statistics reveal what respondents answered: never serve them unauthenticated
+	if cfg.API.Token != "" {
+		server := api.NewServer(cfg.API.Addr, cfg.API.Token, logger)
+		server.Handle(api.StatsPattern, api.NewStatsHandler(core, logger))
+
+		go server.Run()
+		closers = append(closers, server)
+	} else {
+		logger.Warn("API_TOKEN is not set, the statistics API is disabled")
+	}
 
 
 	<- signalChan
 
-	closer := closer.NewShutdown(publisher, casher, consumer)
+	for _, consumer := range consumers {
+		closers = append(closers, consumer)
+	}
+
+	closer := closer.NewShutdown(closers...)
 	closer.ShutdownAll(context.Background())
 }
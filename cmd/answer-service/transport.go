@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/retrier"
+	"github.com/Koyo-os/answer-service/pkg/transport"
+	"github.com/Koyo-os/answer-service/pkg/transport/consumer"
+	"github.com/Koyo-os/answer-service/pkg/transport/inmemory"
+	"github.com/Koyo-os/answer-service/pkg/transport/jetstream"
+	"github.com/Koyo-os/answer-service/pkg/transport/kafka"
+	"github.com/Koyo-os/answer-service/pkg/transport/listener"
+	"github.com/Koyo-os/answer-service/pkg/transport/publisher"
+	"github.com/Koyo-os/answer-service/pkg/transport/redisstream"
+	"github.com/nats-io/nats.go"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// formEventTypes are the form service events kept in the local form replica
+var formEventTypes = []string{
+	listener.EventTypeFormCreated,
+	listener.EventTypeFormUpdated,
+	listener.EventTypeFormDeleted,
+}
+
+// newTransport builds the publisher and consumers for the configured broker driver
+// The first consumer reads request events, the second form service events
+func newTransport(cfg *config.Config, logger *logger.Logger) (transport.Publisher, []transport.Consumer, error) {
+	switch cfg.Transport.Driver {
+	case "", transport.DriverAMQP:
+		return newAMQPTransport(cfg, logger)
+	case transport.DriverKafka:
+		return newKafkaTransport(cfg, logger)
+	case transport.DriverNATS:
+		return newJetStreamTransport(cfg, logger)
+	case transport.DriverRedis:
+		return newRedisStreamTransport(cfg, logger)
+	case transport.DriverMemory:
+		logger.Warn("using in-memory transport, events are not shared with other processes")
+
+		broker := inmemory.NewBroker(cfg.Transport.BufferSize)
+
+		return inmemory.NewPublisher(broker, logger),
+			[]transport.Consumer{
+				inmemory.NewConsumer(broker, logger, inmemory.RequestPrefix),
+				inmemory.NewConsumer(broker, logger, inmemory.FormPrefix),
+			},
+			nil
+	default:
+		return nil, nil, fmt.Errorf("unknown transport driver: %s", cfg.Transport.Driver)
+	}
+}
+
+func newAMQPTransport(cfg *config.Config, logger *logger.Logger) (transport.Publisher, []transport.Consumer, error) {
+	rabbitmqConns, err := retrier.MultiConnects(3, func() (*amqp.Connection, error) {
+		return amqp.Dial(cfg.Urls["rabbitmq"])
+	}, &retrier.RetrierOpts{Count: 3, Interval: 5})
+	if err != nil {
+		logger.Error("error connect to rabbitmq",
+			zap.String("url", cfg.Urls["rabbitmq"]),
+			zap.Error(err))
+
+		return nil, nil, err
+	}
+
+	publisher, err := publisher.Init(cfg, logger, rabbitmqConns[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize publisher: %w", err)
+	}
+
+	requests, err := consumer.Init(cfg, logger, rabbitmqConns[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize consumer: %w", err)
+	}
+
+	forms, err := consumer.InitQueue(cfg, logger, rabbitmqConns[2], cfg.Exchanges["form"], cfg.Queues["form"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize form consumer: %w", err)
+	}
+
+	for _, eventType := range formEventTypes {
+		if err := forms.Subscribe(cfg.Exchanges["form"], eventType, cfg.Queues["form"]); err != nil {
+			return nil, nil, fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+		}
+	}
+
+	return publisher, []transport.Consumer{requests, forms}, nil
+}
+
+func newKafkaTransport(cfg *config.Config, logger *logger.Logger) (transport.Publisher, []transport.Consumer, error) {
+	producer, err := kafka.NewProducer(cfg, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize kafka producer: %w", err)
+	}
+
+	requests, err := kafka.NewConsumer(cfg, logger)
+	if err != nil {
+		producer.Close()
+		return nil, nil, fmt.Errorf("failed to initialize kafka consumer: %w", err)
+	}
+
+	forms, err := kafka.NewTopicConsumer(cfg, logger, cfg.Transport.Kafka.FormTopic)
+	if err != nil {
+		producer.Close()
+		requests.Close()
+		return nil, nil, fmt.Errorf("failed to initialize kafka form consumer: %w", err)
+	}
+
+	return producer, []transport.Consumer{requests, forms}, nil
+}
+
+func newJetStreamTransport(cfg *config.Config, logger *logger.Logger) (transport.Publisher, []transport.Consumer, error) {
+	// Separate connections so draining one side on shutdown doesn't affect the others
+	natsConns, err := retrier.MultiConnects(3, func() (*nats.Conn, error) {
+		return jetstream.Connect(cfg)
+	}, &retrier.RetrierOpts{Count: 3, Interval: 5})
+	if err != nil {
+		logger.Error("error connect to nats",
+			zap.String("url", cfg.Transport.NATS.URL),
+			zap.Error(err))
+
+		return nil, nil, err
+	}
+
+	publisher, err := jetstream.NewPublisher(cfg, logger, natsConns[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize jetstream publisher: %w", err)
+	}
+
+	requests, err := jetstream.NewConsumer(cfg, logger, natsConns[1])
+	if err != nil {
+		publisher.Close()
+		return nil, nil, fmt.Errorf("failed to initialize jetstream consumer: %w", err)
+	}
+
+	forms, err := jetstream.NewFormConsumer(cfg, logger, natsConns[2])
+	if err != nil {
+		publisher.Close()
+		requests.Close()
+		return nil, nil, fmt.Errorf("failed to initialize jetstream form consumer: %w", err)
+	}
+
+	return publisher, []transport.Consumer{requests, forms}, nil
+}
+
+func newRedisStreamTransport(cfg *config.Config, logger *logger.Logger) (transport.Publisher, []transport.Consumer, error) {
+	// Each consumer blocks in XREADGROUP, so each gets its own client
+	redisConns, err := retrier.MultiConnects(3, func() (*redis.Client, error) {
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Urls["redis"],
+			DB:       0,
+			Password: "",
+		})
+
+		return client, client.Ping(context.Background()).Err()
+	}, &retrier.RetrierOpts{Count: 3, Interval: 5})
+	if err != nil {
+		logger.Error("error connect to redis",
+			zap.String("url", cfg.Urls["redis"]),
+			zap.Error(err))
+
+		return nil, nil, err
+	}
+
+	closeAll := func() {
+		for _, conn := range redisConns {
+			conn.Close()
+		}
+	}
+
+	requests, err := redisstream.NewConsumer(cfg, logger, redisConns[1])
+	if err != nil {
+		closeAll()
+		return nil, nil, fmt.Errorf("failed to initialize redis stream consumer: %w", err)
+	}
+
+	forms, err := redisstream.NewStreamConsumer(cfg, logger, redisConns[2], cfg.Transport.RedisStreams.FormStream)
+	if err != nil {
+		closeAll()
+		return nil, nil, fmt.Errorf("failed to initialize redis stream form consumer: %w", err)
+	}
+
+	return redisstream.NewPublisher(cfg, logger, redisConns[0]), []transport.Consumer{requests, forms}, nil
+}
@@ -0,0 +1,118 @@
+// cache-warmer is an admin command that rebuilds the answer cache from the
+// database once and exits.
+//
+// Usage:
+//
+//	cache-warmer [-forms id1,id2] [-since 24h] [-batch 500]
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/internal/database"
+	"github.com/Koyo-os/answer-service/internal/repository"
+	"github.com/Koyo-os/answer-service/internal/warmer"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/retrier"
+	"github.com/Koyo-os/answer-service/pkg/transport/casher"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func main() {
+	forms := flag.String("forms", "", "comma separated form IDs to warm (default: all forms)")
+	since := flag.Duration("since", 0, "only warm answers updated within this window (default: all answers)")
+	batch := flag.Int("batch", warmer.DefaultBatchSize, "number of answers per batch")
+	flag.Parse()
+
+	logCfg := logger.Config{
+		LogLevel:  "info",
+		AppName:   "answer-service-cache-warmer",
+		AddCaller: true,
+	}
+
+	if err := logger.Init(logCfg); err != nil {
+		panic(err)
+	}
+
+	defer logger.Sync()
+
+	logger := logger.Get()
+
+	cfg := config.NewConfig()
+	cfg.Warmer.BatchSize = *batch
+
+	filter := repository.AnswerFilter{}
+
+	if *since > 0 {
+		filter.Since = time.Now().Add(-*since)
+	}
+
+	if *forms != "" {
+		for _, id := range strings.Split(*forms, ",") {
+			formID, err := uuid.Parse(strings.TrimSpace(id))
+			if err != nil {
+				logger.Error("invalid form id", zap.String("form_id", id), zap.Error(err))
+				os.Exit(2)
+			}
+			filter.FormIDs = append(filter.FormIDs, formID)
+		}
+	}
+
+	dsn, err := database.DSN(cfg.Database.Dialect)
+	if err != nil {
+		logger.Error("invalid database config", zap.Error(err))
+		os.Exit(2)
+	}
+
+	db, err := retrier.Connect(3, 5, func() (*gorm.DB, error) {
+		return database.Open(cfg.Database.Dialect, dsn)
+	})
+	if err != nil {
+		logger.Error("error initialyze database", zap.Error(err))
+		os.Exit(1)
+	}
+
+	redisConn, err := retrier.Connect(3, 5, func() (*redis.Client, error) {
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Urls["redis"],
+			DB:       0,
+			Password: "",
+		})
+
+		return client, client.Ping(context.Background()).Err()
+	})
+	if err != nil {
+		logger.Error("error connect to redis", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// The local layer only matters for a long-running service
+	cfg.Cache.Local.Enabled = false
+
+	casher, err := casher.Init(redisConn, logger, cfg)
+	if err != nil {
+		logger.Error("error initialize casher", zap.Error(err))
+		os.Exit(1)
+	}
+	defer casher.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	w := warmer.NewWarmer(repository.NewRepository(db, logger), casher, logger, cfg)
+
+	if _, err := w.Warm(ctx, filter); err != nil {
+		logger.Error("cache warm-up failed", zap.Error(err))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultShutdownTimeout bounds waiting for in-flight requests on Close
+const DefaultShutdownTimeout = 10 * time.Second
+
+// Server serves the API routes to requests authenticated with a bearer
+// token, on a listener of its own so they are never exposed next to the
+// health endpoints
+type Server struct {
+	mux    *http.ServeMux
+	server *http.Server
+	token  string
+	logger *logger.Logger
+}
+
+func NewServer(addr, token string, logger *logger.Logger) *Server {
+	s := &Server{
+		mux:    http.NewServeMux(),
+		token:  token,
+		logger: logger,
+	}
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return s
+}
+
+// Handle serves handler for pattern to authenticated requests
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// ServeHTTP rejects requests without the configured bearer token. An empty
+// token rejects every request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		if err := writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"}); err != nil {
+			s.logger.Error("error encode response", zap.Error(err))
+		}
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// Run listens until Close is called
+func (s *Server) Run() {
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.Error("error run api server",
+			zap.String("addr", s.server.Addr),
+			zap.Error(err))
+	}
+}
+
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+	defer cancel()
+
+	return s.server.Shutdown(ctx)
+}
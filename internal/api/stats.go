@@ -0,0 +1,82 @@
+// Package api serves the HTTP read endpoints of the service
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/stats"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// StatsPattern is the route of the form statistics endpoint
+const StatsPattern = "GET /forms/{id}/stats"
+
+// DefaultStatsTimeout bounds computing the statistics of one form
+const DefaultStatsTimeout = 30 * time.Second
+
+type (
+	StatsService interface {
+		FormStats(context.Context, uuid.UUID) (*stats.FormStats, error)
+	}
+
+	// StatsHandler serves the aggregate statistics of a form's answers
+	StatsHandler struct {
+		service StatsService
+		logger  *logger.Logger
+	}
+
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+func NewStatsHandler(service StatsService, logger *logger.Logger) *StatsHandler {
+	return &StatsHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *StatsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	formID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.write(w, http.StatusBadRequest, errorResponse{Error: "invalid form ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), DefaultStatsTimeout)
+	defer cancel()
+
+	result, err := h.service.FormStats(ctx, formID)
+	switch {
+	case errors.Is(err, entity.ErrFormNotFound):
+		h.write(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+	case err != nil:
+		h.logger.Error("failed to get form statistics",
+			zap.String("form_id", formID.String()),
+			zap.Error(err))
+		h.write(w, http.StatusInternalServerError, errorResponse{Error: "failed to compute statistics"})
+	default:
+		h.write(w, http.StatusOK, result)
+	}
+}
+
+func (h *StatsHandler) write(w http.ResponseWriter, status int, body any) {
+	if err := writeJSON(w, status, body); err != nil {
+		h.logger.Error("error encode response", zap.Error(err))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(body)
+}
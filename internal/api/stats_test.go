@@ -0,0 +1,94 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/api"
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/stats"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// fakeStats serves the statistics of known forms and fails for broken ones
+type fakeStats struct {
+	known  map[uuid.UUID]*stats.FormStats
+	broken uuid.UUID
+}
+
+func (f *fakeStats) FormStats(_ context.Context, formID uuid.UUID) (*stats.FormStats, error) {
+	if formID == f.broken {
+		return nil, errors.New("database is down")
+	}
+
+	result, ok := f.known[formID]
+	if !ok {
+		return nil, entity.ErrFormNotFound
+	}
+	return result, nil
+}
+
+func TestStatsHandler(t *testing.T) {
+	known := &stats.FormStats{FormID: uuid.New(), Responses: 2, Completed: 1, CompletionRate: 0.5}
+	service := &fakeStats{
+		known:  map[uuid.UUID]*stats.FormStats{known.FormID: known},
+		broken: uuid.New(),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(api.StatsPattern, api.NewStatsHandler(service, logger.Get()))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{"known form", known.FormID.String(), http.StatusOK},
+		{"invalid ID", "not-a-uuid", http.StatusBadRequest},
+		{"unknown form", uuid.NewString(), http.StatusNotFound},
+		{"service failure", service.broken.String(), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + "/forms/" + tt.id + "/stats")
+			if err != nil {
+				t.Fatalf("GET: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Fatalf("Content-Type = %q, want application/json", ct)
+			}
+
+			if tt.status != http.StatusOK {
+				body := struct {
+					Error string `json:"error"`
+				}{}
+				if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+					t.Fatalf("error body = %+v, %v, want an error message", body, err)
+				}
+				return
+			}
+
+			got := new(stats.FormStats)
+			if err := json.NewDecoder(resp.Body).Decode(got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.FormID != known.FormID || got.Responses != 2 || got.CompletionRate != 0.5 {
+				t.Fatalf("stats = %+v, want %+v", got, known)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
-import "time"
+import (
+	"os"
+	"time"
+)
 
 type (
 	Exchanges map[string]string
@@ -19,12 +22,156 @@ type (
 
 	Urls map[string]string
 
+	// Cache configures how the casher encodes values and how long
+	// they live. TTLs maps a key prefix (e.g. "answer:") to its
+	// expiration; keys matching no prefix fall back to DefaultTTL.
+	// NegativeTTL bounds how long a "not found" result is remembered,
+	// and EarlyRefreshBeta scales probabilistic early refresh
+	// (0 disables it, values above 1 favour earlier refreshes).
+	// LoadTimeout bounds a load shared by concurrent misses, which runs
+	// apart from the callers' contexts.
+	Cache struct {
+		Codec            string
+		DefaultTTL       time.Duration
+		TTLs             map[string]time.Duration
+		NegativeTTL      time.Duration
+		EarlyRefreshBeta float64
+		LoadTimeout      time.Duration
+		Local            LocalCache
+	}
+
+	// LocalCache configures the optional in-process layer in front of
+	// Redis. Replicas invalidate each other's entries through the
+	// InvalidationChannel pub/sub channel. Size and TTL must be positive
+	// when it is enabled.
+	LocalCache struct {
+		Enabled             bool
+		Size                int
+		TTL                 time.Duration
+		InvalidationChannel string
+	}
+
+	// Warmer configures repopulating the answer cache from the database.
+	// Interval 0 disables scheduled runs; RecentWindow and FormIDs limit
+	// which answers are loaded (zero values load everything).
+	Warmer struct {
+		OnStartup    bool
+		Interval     time.Duration
+		BatchSize    int
+		RecentWindow time.Duration
+		FormIDs      []string
+	}
+
+	// Transport selects the event broker: "amqp" (RabbitMQ), "kafka",
+	// "nats" (JetStream), "redis" (Redis Streams) or "memory" (in-process,
+	// for local development and tests)
+	Transport struct {
+		Driver       string
+		BufferSize   int
+		Kafka        Kafka
+		NATS         NATS
+		RedisStreams RedisStreams
+	}
+
+	// Kafka configures the Kafka transport. Requests are read from
+	// RequestTopic and form service events from FormTopic by the GroupID
+	// consumer group; results are written to OutputTopic.
+	Kafka struct {
+		Brokers      []string
+		RequestTopic string
+		FormTopic    string
+		OutputTopic  string
+		GroupID      string
+	}
+
+	// Database selects the SQL dialect: "mariadb", "postgres" or "sqlite".
+	// Connection details come from the DB_* environment variables.
+	Database struct {
+		Dialect string
+	}
+
+	// NATS configures the JetStream transport. Requests published on
+	// RequestSubjects are read by the Durable consumer of RequestStream
+	// and form service events by a second durable on FormStream;
+	// results go to OutputStream. Unacknowledged messages are redelivered
+	// after AckWait, at most MaxDeliver times.
+	NATS struct {
+		URL             string
+		RequestStream   string
+		RequestSubjects string
+		FormStream      string
+		FormSubjects    string
+		OutputStream    string
+		OutputSubjects  string
+		Durable         string
+		AckWait         time.Duration
+		MaxDeliver      int
+	}
+
+	// RedisStreams configures the Redis Streams transport, which uses the
+	// "redis" URL. Requests are read from RequestStream and form service
+	// events from FormStream by the Group consumer group; entries pending
+	// longer than ClaimIdle (e.g. after a crash or a failed handling) are
+	// reclaimed, until they were delivered MaxDeliveries times; then they
+	// are acknowledged and dropped. OutputStream is trimmed to about MaxLen
+	// entries.
+	RedisStreams struct {
+		RequestStream string
+		FormStream    string
+		OutputStream  string
+		Group         string
+		Consumer      string
+		MaxLen        int64
+		BatchSize     int64
+		Block         time.Duration
+		ClaimIdle     time.Duration
+		MaxDeliveries int64
+	}
+
+	// Events configures the envelope of published events. Mode is
+	// "structured" or "binary" (CloudEvents 1.0) or "legacy"; consumed
+	// events are accepted in any of them. Source is the CloudEvents source.
+	// Encoding is "json" or "protobuf" (application/x-protobuf, AMQP only).
+	Events struct {
+		Source   string
+		Mode     string
+		Encoding string
+	}
+
+	// Forms configures where the form definitions answers are checked
+	// against come from. The local replica is fed by form service events;
+	// forms missing from it (e.g. created before the replica existed) are
+	// fetched from ServiceURL when set and stored in the replica. Answers
+	// to forms found nowhere are rejected when RequireKnown is set and
+	// stored unchecked otherwise.
+	Forms struct {
+		ServiceURL   string
+		Timeout      time.Duration
+		RequireKnown bool
+	}
+
+	// API configures the HTTP read endpoints (form statistics). They are
+	// served on Addr, apart from the unauthenticated health listener, and
+	// only to requests carrying Token as a bearer token; without a Token
+	// the API isn't served at all.
+	API struct {
+		Addr  string
+		Token string
+	}
+
 	Config struct {
 		Exchanges   Exchanges
 		Queues      Queues
 		RetrierOpts RetrierOpts
 		Urls        Urls
 		HealthCheck HealthCheck
+		Cache       Cache
+		Warmer      Warmer
+		Transport   Transport
+		Database    Database
+		Events      Events
+		Forms       Forms
+		API         API
 	}
 )
 
@@ -50,5 +197,89 @@ func NewConfig() *Config {
 			Port: "8080",
 			Use:  true,
 		},
+		Cache: Cache{
+			Codec:      "json",
+			DefaultTTL: time.Hour,
+			TTLs: map[string]time.Duration{
+				"answer:": 24 * time.Hour,
+				"form:":   24 * time.Hour,
+				"stats:":  10 * time.Minute,
+			},
+			NegativeTTL:      30 * time.Second,
+			EarlyRefreshBeta: 1.0,
+			LoadTimeout:      30 * time.Second,
+			Local: LocalCache{
+				Enabled:             false,
+				Size:                10000,
+				TTL:                 30 * time.Second,
+				InvalidationChannel: "casher:invalidate",
+			},
+		},
+		Warmer: Warmer{
+			OnStartup:    true,
+			Interval:     0,
+			BatchSize:    500,
+			RecentWindow: 7 * 24 * time.Hour,
+		},
+		Transport: Transport{
+			Driver:     getEnv("TRANSPORT_DRIVER", "amqp"),
+			BufferSize: 100,
+			Kafka: Kafka{
+				Brokers:      []string{"kafka:9092"},
+				RequestTopic: "answer.requests",
+				FormTopic:    "form.events",
+				OutputTopic:  "answer.events",
+				GroupID:      "answer-service",
+			},
+			NATS: NATS{
+				URL:             "nats://nats:4222",
+				RequestStream:   "ANSWER_REQUESTS",
+				RequestSubjects: "request.answer.*",
+				FormStream:      "FORM_EVENTS",
+				FormSubjects:    "form.*",
+				OutputStream:    "ANSWER_EVENTS",
+				OutputSubjects:  "answer.*",
+				Durable:         "answer-service",
+				AckWait:         30 * time.Second,
+				MaxDeliver:      5,
+			},
+			RedisStreams: RedisStreams{
+				RequestStream: "answer:requests",
+				FormStream:    "form:events",
+				OutputStream:  "answer:events",
+				Group:         "answer-service",
+				Consumer:      getEnv("HOSTNAME", "answer-service"),
+				MaxLen:        100000,
+				BatchSize:     10,
+				Block:         5 * time.Second,
+				ClaimIdle:     time.Minute,
+				MaxDeliveries: 5,
+			},
+		},
+		Database: Database{
+			Dialect: getEnv("DB_DIALECT", "mariadb"),
+		},
+		Events: Events{
+			Source:   "/answer-service",
+			Mode:     getEnv("EVENTS_MODE", "structured"),
+			Encoding: getEnv("EVENTS_ENCODING", "json"),
+		},
+		Forms: Forms{
+			ServiceURL:   getEnv("FORM_SERVICE_URL", ""),
+			Timeout:      5 * time.Second,
+			RequireKnown: getEnv("FORMS_REQUIRE_KNOWN", "false") == "true",
+		},
+		API: API{
+			Addr:  getEnv("API_ADDR", ":8081"),
+			Token: getEnv("API_TOKEN", ""),
+		},
 	}
 }
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+
+	return fallback
+}
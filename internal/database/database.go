@@ -0,0 +1,128 @@
+// Package database opens the gorm connection for the configured SQL dialect
+// and migrates the answer and form tables with column types that dialect supports
+package database
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	DialectMariaDB  = "mariadb"
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// uuidTypes maps the `gorm:"type:uuid"` columns of the entities to the
+// native type of each dialect. MariaDB and SQLite have no usable uuid type,
+// so the canonical 36-character text form is stored instead.
+var uuidTypes = map[string]schema.DataType{
+	DialectMariaDB:  "char(36)",
+	DialectPostgres: "uuid",
+	DialectSQLite:   "text",
+}
+
+// Models lists every entity stored by the service, in migration order
+var Models = []any{&entity.Answer{}, &entity.Element{}, &entity.Form{}, &entity.Question{}, &entity.FormLock{}}
+
+// DSN builds the connection string for dialect from the DB_* environment variables
+// For SQLite, DB_NAME is the database file path (":memory:" for a throwaway database)
+func DSN(dialect string) (string, error) {
+	var (
+		user     = os.Getenv("DB_USER")
+		password = os.Getenv("DB_PASSWORD")
+		host     = os.Getenv("DB_HOST")
+		port     = os.Getenv("DB_PORT")
+		name     = os.Getenv("DB_NAME")
+	)
+
+	switch dialect {
+	case DialectMariaDB:
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			user, password, host, port, name), nil
+	case DialectPostgres:
+		return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			host, port, user, password, name), nil
+	case DialectSQLite:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown database dialect: %s", dialect)
+	}
+}
+
+// Dialector returns the gorm dialector for dialect and dsn
+func Dialector(dialect, dsn string) (gorm.Dialector, error) {
+	switch dialect {
+	case DialectMariaDB:
+		return mysql.Open(dsn), nil
+	case DialectPostgres:
+		return postgres.Open(dsn), nil
+	case DialectSQLite:
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unknown database dialect: %s", dialect)
+	}
+}
+
+// Open connects to the database and maps the entity UUID columns for dialect
+func Open(dialect, dsn string) (*gorm.DB, error) {
+	dialector, err := Dialector(dialect, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mapUUIDColumns(db, dialect); err != nil {
+		return nil, fmt.Errorf("failed to map uuid columns: %w", err)
+	}
+
+	return db, nil
+}
+
+// Migrate creates or updates the tables of every model
+// Elements stored before typed values existed are marked as strings
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(Models...); err != nil {
+		return err
+	}
+
+	return db.Model(&entity.Element{}).
+		Where("value_type IS NULL OR value_type = ''").
+		Update("value_type", entity.ValueTypeString).Error
+}
+
+// mapUUIDColumns rewrites the data type of uuid columns in the parsed model
+// schemas. gorm caches parsed schemas per connection, so migrations and
+// queries made through db afterwards see the dialect's type.
+func mapUUIDColumns(db *gorm.DB, dialect string) error {
+	uuidType, ok := uuidTypes[dialect]
+	if !ok {
+		return fmt.Errorf("unknown database dialect: %s", dialect)
+	}
+
+	for _, model := range Models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DataType == "uuid" {
+				field.DataType = uuidType
+			}
+		}
+	}
+
+	return nil
+}
@@ -9,22 +9,41 @@ import (
 )
 
 type (
+	// Element is the answer to one question. Content always holds the value
+	// as text; ValueType says which typed column holds it natively (see value.go).
+	// Correct and Points grade the element of a scored quiz answer.
 	Element struct {
 		gorm.Model
-		AnswerID            uuid.UUID `gorm:"type:uuid" json:"answer_id"`
-		QuestionOrderNumber uint      `gorm:"type:integer" json:"question_order_number"`
-		Content             string    `gorm:"type:text" json:"content"`
-		Answer              Answer    `gorm:"foreignKey:AnswerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+		AnswerID            uuid.UUID  `gorm:"type:uuid" json:"answer_id"`
+		QuestionOrderNumber uint       `gorm:"type:integer" json:"question_order_number"`
+		Content             string     `gorm:"type:text" json:"content"`
+		ValueType           string     `gorm:"type:varchar(16);not null;default:string" json:"value_type"`
+		NumberValue         *float64   `json:"-"`
+		BoolValue           *bool      `json:"-"`
+		DateValue           *time.Time `json:"-"`
+		ListValue           []string   `gorm:"serializer:json;type:text" json:"-"`
+		JSONValue           string     `gorm:"type:text" json:"-"`
+		Correct             *bool      `json:"correct,omitempty"`
+		Points              *float64   `json:"points,omitempty"`
+		Answer              Answer     `gorm:"foreignKey:AnswerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
 	}
 
+	// Answer is one user's response to a form
+	// Respondent repeats UserID for answers to single-response forms; the
+	// unique index on (FormID, Respondent) keeps a second one from being
+	// stored. It is NULL, and so never conflicts, for other forms.
+	// Score and MaxScore are set once an answer to a quiz form is complete.
 	Answer struct {
-		ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-		CreatedAt  time.Time `json:"created_at"`
-		UpdatedAt  time.Time `json:"updated_at"`
-		FormID     uuid.UUID `gorm:"type:uuid" json:"form_id"`
-		UserID     uuid.UUID `gorm:"type:uuid" json:"user_id"`
-		IsComplete bool      `gorm:"default:false" json:"is_complete"`
-		Elements   []Element `gorm:"foreignKey:AnswerID" json:"elements"`
+		ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+		CreatedAt  time.Time  `json:"created_at"`
+		UpdatedAt  time.Time  `json:"updated_at"`
+		FormID     uuid.UUID  `gorm:"type:uuid;uniqueIndex:idx_answers_form_respondent,priority:1" json:"form_id"`
+		UserID     uuid.UUID  `gorm:"type:uuid" json:"user_id"`
+		Respondent *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_answers_form_respondent,priority:2" json:"-"`
+		IsComplete bool       `gorm:"default:false" json:"is_complete"`
+		Score      *float64   `json:"score,omitempty"`
+		MaxScore   *float64   `json:"max_score,omitempty"`
+		Elements   []Element  `gorm:"foreignKey:AnswerID" json:"elements"`
 	}
 )
 
@@ -56,12 +75,13 @@ func (a *Answer) GetElementByQuestionOrder(orderNumber uint) *Element {
 	return nil
 }
 
-// AddElement adds a new element to the answer
+// AddElement adds a new text element to the answer
 func (a *Answer) AddElement(questionOrder uint, content string) {
 	element := Element{
 		AnswerID:            a.ID,
 		QuestionOrderNumber: questionOrder,
 		Content:             content,
+		ValueType:           ValueTypeString,
 	}
 	a.Elements = append(a.Elements, element)
 }
@@ -109,4 +129,10 @@ var (
 	ErrInvalidUserID   = fmt.Errorf("invalid user ID")
 	ErrInvalidAnswerID = fmt.Errorf("invalid answer ID")
 	ErrEmptyContent    = fmt.Errorf("element content cannot be empty")
+	ErrAnswerNotFound  = fmt.Errorf("answer not found")
+	// ErrAlreadySubmitted is returned when a user answers a single-response form twice
+	ErrAlreadySubmitted = fmt.Errorf("user has already answered the form")
+	// ErrInvalidID is returned for a requested ID that isn't a UUID
+	ErrInvalidID = fmt.Errorf("invalid answer ID format")
+	ErrAnswerNil = fmt.Errorf("answer cannot be nil")
 )
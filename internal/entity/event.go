@@ -7,19 +7,55 @@ import (
 	"github.com/google/uuid"
 )
 
+// InitialSchemaVersion is the payload version of events that don't carry one
+const InitialSchemaVersion = 1
+
+// Event is the envelope of every message the service consumes or publishes.
+// Source, Subject and DataContentType carry the matching CloudEvents
+// attributes; they are empty for events parsed from the legacy envelope.
+// SchemaVersion is the version of the Payload shape for this Type.
+// ReplyTo and CorrelationID come from the transport of a request that asks
+// for a reply; they are never part of the encoded event.
+// Ack is set by transports that acknowledge messages explicitly; see Settle.
 type Event struct {
-	ID        string    `json:"id"`
-	Payload   []byte    `json:"payload"`
-	Type      string    `json:"type"`
-	Timestamp time.Time `json:"timestamp"`
+	ID              string             `json:"id"`
+	Payload         []byte             `json:"payload"`
+	Type            string             `json:"type"`
+	Timestamp       time.Time          `json:"timestamp"`
+	SchemaVersion   int                `json:"schema_version,omitempty"`
+	Source          string             `json:"source,omitempty"`
+	Subject         string             `json:"subject,omitempty"`
+	DataContentType string             `json:"datacontenttype,omitempty"`
+	ReplyTo         string             `json:"-"`
+	CorrelationID   string             `json:"-"`
+	Ack             func(requeue bool) `json:"-"`
 }
 
 func NewEvent(Type string, payload []byte) *Event {
 	return &Event{
-		ID:        uuid.New().String(),
-		Payload:   payload,
-		Type:      Type,
-		Timestamp: time.Now(),
+		ID:            uuid.New().String(),
+		Payload:       payload,
+		Type:          Type,
+		Timestamp:     time.Now(),
+		SchemaVersion: InitialSchemaVersion,
+	}
+}
+
+// Version returns the payload schema version, treating a missing one as
+// InitialSchemaVersion (events published before versioning existed)
+func (e *Event) Version() int {
+	if e.SchemaVersion <= 0 {
+		return InitialSchemaVersion
+	}
+	return e.SchemaVersion
+}
+
+// Settle acknowledges the transport message the event was read from once
+// it has been handled; requeue asks for redelivery after a transient failure.
+// It is a no-op for transports without explicit acknowledgement.
+func (e *Event) Settle(requeue bool) {
+	if e.Ack != nil {
+		e.Ack(requeue)
 	}
 }
 
@@ -0,0 +1,191 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Question types defined by the form service
+const (
+	QuestionTypeText           = "text"
+	QuestionTypeNumber         = "number"
+	QuestionTypeDate           = "date"
+	QuestionTypeEmail          = "email"
+	QuestionTypeRating         = "rating"
+	QuestionTypeSingleChoice   = "single_choice"
+	QuestionTypeMultipleChoice = "multiple_choice"
+)
+
+// Skip-logic condition operators
+const (
+	ConditionEquals      = "equals"
+	ConditionNotEquals   = "not_equals"
+	ConditionIn          = "in"
+	ConditionContains    = "contains" // multiple choice answer includes Value
+	ConditionAnswered    = "answered"
+	ConditionNotAnswered = "not_answered"
+)
+
+// Response policies: how many answers a user may submit to a form
+const (
+	ResponsePolicyMultiple       = "multiple"        // any number (the default)
+	ResponsePolicySingle         = "single"          // one; later submissions are rejected
+	ResponsePolicySingleEditable = "single_editable" // one; later submissions replace it
+)
+
+// DefaultQuestionPoints is what a quiz question setting no points is worth
+const DefaultQuestionPoints = 1
+
+// Ways a visibility rule combines its conditions
+const (
+	MatchAll = "all"
+	MatchAny = "any"
+)
+
+type (
+	// Form is the local replica of a form owned by the form service,
+	// kept up to date from its form.created/updated/deleted events
+	Form struct {
+		ID    uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+		Title string    `gorm:"type:text" json:"title"`
+		// One of the ResponsePolicy* constants; empty means multiple
+		ResponsePolicy string `gorm:"type:varchar(32);not null;default:multiple" json:"response_policy,omitempty"`
+		// Answers are accepted from OpensAt until ClosesAt; either may be unset
+		OpensAt  *time.Time `json:"opens_at,omitempty"`
+		ClosesAt *time.Time `json:"closes_at,omitempty"`
+		// Most answers the form accepts; 0 means no cap
+		MaxResponses int `gorm:"not null;default:0" json:"max_responses,omitempty"`
+		// Set by the form service; events older than the replica are ignored
+		UpdatedAt time.Time `gorm:"autoUpdateTime:false" json:"updated_at"`
+		// Set on the tombstone a deletion leaves, so late events can't revive it
+		DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+		Questions []Question     `gorm:"foreignKey:FormID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"questions"`
+	}
+
+	// Question is one question of a replicated form, identified within the
+	// form by its order number (Element.QuestionOrderNumber refers to it).
+	// Min and Max bound numbers and ratings or the length of text;
+	// Pattern is a regular expression the content must match.
+	// VisibleIf hides the question unless its rule holds (skip logic).
+	// Correct lists the accepted answers of a quiz question (for multiple
+	// choice, the exact set of options to choose), worth Points when right.
+	Question struct {
+		FormID      uuid.UUID       `gorm:"type:uuid;primaryKey" json:"-"`
+		OrderNumber uint            `gorm:"primaryKey;autoIncrement:false" json:"order_number"`
+		Type        string          `gorm:"type:varchar(32)" json:"type"`
+		Required    bool            `gorm:"default:false" json:"required"`
+		Options     []string        `gorm:"serializer:json;type:text" json:"options,omitempty"`
+		Min         *float64        `json:"min,omitempty"`
+		Max         *float64        `json:"max,omitempty"`
+		Pattern     string          `gorm:"type:text" json:"pattern,omitempty"`
+		VisibleIf   *VisibilityRule `gorm:"serializer:json;type:text" json:"visible_if,omitempty"`
+		Correct     []string        `gorm:"serializer:json;type:text" json:"correct,omitempty"`
+		Points      float64         `gorm:"not null;default:0" json:"points,omitempty"`
+	}
+
+	// VisibilityRule shows a question only when its conditions on earlier
+	// answers hold; Match is MatchAll (the default) or MatchAny
+	VisibilityRule struct {
+		Match      string      `json:"match,omitempty"`
+		Conditions []Condition `json:"conditions"`
+	}
+
+	// Condition compares the answer to another question of the form
+	Condition struct {
+		Question uint     `json:"question"`
+		Operator string   `json:"operator"`
+		Value    string   `json:"value,omitempty"`
+		Values   []string `json:"values,omitempty"`
+	}
+
+	// FormLock is the row capped submissions to a form lock while counting
+	// its answers. It is created on first use, so it exists whether or not
+	// the form was ever replicated.
+	FormLock struct {
+		FormID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	}
+)
+
+// TableName returns the table name for FormLock
+func (FormLock) TableName() string {
+	return "form_locks"
+}
+
+// TableName returns the table name for Form
+func (Form) TableName() string {
+	return "forms"
+}
+
+// TableName returns the table name for Question
+func (Question) TableName() string {
+	return "form_questions"
+}
+
+// GetQuestion returns the question with the given order number
+func (f *Form) GetQuestion(orderNumber uint) *Question {
+	for i := range f.Questions {
+		if f.Questions[i].OrderNumber == orderNumber {
+			return &f.Questions[i]
+		}
+	}
+	return nil
+}
+
+// SingleResponse reports whether each user may hold only one answer to the form
+func (f *Form) SingleResponse() bool {
+	return f.ResponsePolicy == ResponsePolicySingle || f.ResponsePolicy == ResponsePolicySingleEditable
+}
+
+// IsQuiz reports whether any question of the form has correct answers
+func (f *Form) IsQuiz() bool {
+	for i := range f.Questions {
+		if f.Questions[i].Scored() {
+			return true
+		}
+	}
+	return false
+}
+
+// Scored reports whether the question is graded
+func (q *Question) Scored() bool {
+	return len(q.Correct) > 0
+}
+
+// Worth returns the points a correct answer to the question earns
+func (q *Question) Worth() float64 {
+	if q.Points > 0 {
+		return q.Points
+	}
+	return DefaultQuestionPoints
+}
+
+// CheckOpen returns ErrFormNotOpen or ErrFormClosed if the form doesn't
+// accept answers at the given time
+func (f *Form) CheckOpen(at time.Time) error {
+	if f.OpensAt != nil && at.Before(*f.OpensAt) {
+		return ErrFormNotOpen
+	}
+	if f.ClosesAt != nil && !at.Before(*f.ClosesAt) {
+		return ErrFormClosed
+	}
+	return nil
+}
+
+// Validate performs basic validation on the Form
+func (f *Form) Validate() error {
+	if f.ID == uuid.Nil {
+		return ErrInvalidFormID
+	}
+	return nil
+}
+
+var (
+	ErrFormNotFound = fmt.Errorf("form not found")
+	ErrFormDeleted  = fmt.Errorf("form was deleted")
+	ErrFormNotOpen  = fmt.Errorf("form is not open for answers yet")
+	ErrFormClosed   = fmt.Errorf("form is closed for answers")
+	ErrFormFull     = fmt.Errorf("form has reached its maximum number of answers")
+)
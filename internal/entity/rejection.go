@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Reasons a request event can be rejected for
+const (
+	RejectReasonInvalidPayload   = "invalid_payload"
+	RejectReasonFormNotFound     = "form_not_found"
+	RejectReasonFormDeleted      = "form_deleted"
+	RejectReasonInvalidAnswer    = "invalid_answer"
+	RejectReasonIncomplete       = "incomplete_answer"
+	RejectReasonAlreadySubmitted = "already_submitted"
+	RejectReasonFormNotOpen      = "form_not_open"
+	RejectReasonFormClosed       = "form_closed"
+	RejectReasonFormFull         = "form_full"
+)
+
+type (
+	// FieldError describes one invalid field of an event payload
+	// Field is a dotted path into the payload, e.g. "elements.0.content"
+	FieldError struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	}
+
+	// Rejection is the payload of an answer.rejected event, sent when a
+	// request event is refused before reaching the repository
+	// MissingQuestions lists the unanswered required questions of an
+	// incomplete_answer rejection
+	Rejection struct {
+		RequestID        string       `json:"request_id"`
+		RequestType      string       `json:"request_type"`
+		Requester        string       `json:"requester,omitempty"`
+		AnswerID         string       `json:"answer_id,omitempty"`
+		Reason           string       `json:"reason"`
+		Errors           []FieldError `json:"errors,omitempty"`
+		MissingQuestions []uint       `json:"missing_questions,omitempty"`
+		RejectedAt       time.Time    `json:"rejected_at"`
+	}
+)
+
+// RejectError is returned by the service when it refuses a request for a
+// reason the requester should be told about; the listener turns it into an
+// answer.rejected event
+// Missing holds the order numbers of unanswered required questions;
+// Err, if set, is the sentinel error the rejection stands for
+type RejectError struct {
+	Reason  string
+	Fields  []FieldError
+	Missing []uint
+	Err     error
+}
+
+func (e *RejectError) Error() string {
+	if len(e.Fields) == 0 {
+		return "request rejected: " + e.Reason
+	}
+
+	parts := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		parts[i] = field.Field + ": " + field.Message
+	}
+
+	return fmt.Sprintf("request rejected: %s: %s", e.Reason, strings.Join(parts, "; "))
+}
+
+func (e *RejectError) Unwrap() error {
+	return e.Err
+}
+
+// NewRejection creates a rejection of the request event for the given reason
+func NewRejection(request *Event, reason string, errors ...FieldError) *Rejection {
+	return &Rejection{
+		RequestID:   request.ID,
+		RequestType: request.Type,
+		Requester:   request.Source,
+		AnswerID:    request.Subject,
+		Reason:      reason,
+		Errors:      errors,
+		RejectedAt:  time.Now(),
+	}
+}
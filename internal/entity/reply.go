@@ -0,0 +1,83 @@
+package entity
+
+import "errors"
+
+// Reply statuses
+const (
+	ReplyStatusOK    = "ok"
+	ReplyStatusError = "error"
+)
+
+// Error codes carried by error replies
+const (
+	ReplyCodeInvalidPayload = RejectReasonInvalidPayload
+	ReplyCodeInvalidID      = "invalid_id"
+	ReplyCodeNotFound       = "not_found"
+	ReplyCodeInternal       = "internal"
+)
+
+type (
+	// Reply is the response to a request event that asked for one (AMQP ReplyTo)
+	Reply struct {
+		RequestID string      `json:"request_id"`
+		Status    string      `json:"status"`
+		AnswerID  string      `json:"answer_id,omitempty"`
+		Answer    *Answer     `json:"answer,omitempty"`
+		Error     *ReplyError `json:"error,omitempty"`
+	}
+
+	// ReplyError describes why a request failed
+	ReplyError struct {
+		Code             string       `json:"code"`
+		Message          string       `json:"message"`
+		Fields           []FieldError `json:"fields,omitempty"`
+		MissingQuestions []uint       `json:"missing_questions,omitempty"`
+	}
+)
+
+// NewOKReply creates a successful reply to the request event
+func NewOKReply(request *Event, answerID string, answer *Answer) *Reply {
+	return &Reply{
+		RequestID: request.ID,
+		Status:    ReplyStatusOK,
+		AnswerID:  answerID,
+		Answer:    answer,
+	}
+}
+
+// NewErrorReply creates a failed reply to the request event
+func NewErrorReply(request *Event, code, message string, fields ...FieldError) *Reply {
+	return &Reply{
+		RequestID: request.ID,
+		Status:    ReplyStatusError,
+		AnswerID:  request.Subject,
+		Error: &ReplyError{
+			Code:    code,
+			Message: message,
+			Fields:  fields,
+		},
+	}
+}
+
+// Retryable reports whether the request failed for a transient reason,
+// so the request event should be redelivered rather than dropped
+func (r *Reply) Retryable() bool {
+	return r != nil && r.Error != nil && r.Error.Code == ReplyCodeInternal
+}
+
+// ReplyCode maps an error that isn't a RejectError to the code sent in
+// error replies
+func ReplyCode(err error) string {
+	switch {
+	case errors.Is(err, ErrAnswerNotFound):
+		return ReplyCodeNotFound
+	case errors.Is(err, ErrInvalidID):
+		return ReplyCodeInvalidID
+	case errors.Is(err, ErrAnswerNil),
+		errors.Is(err, ErrInvalidFormID),
+		errors.Is(err, ErrInvalidUserID):
+		return ReplyCodeInvalidPayload
+	default:
+		return ReplyCodeInternal
+	}
+}
@@ -0,0 +1,275 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Element value types
+const (
+	ValueTypeString = "string"
+	ValueTypeNumber = "number"
+	ValueTypeBool   = "bool"
+	ValueTypeDate   = "date"
+	ValueTypeList   = "list"
+	ValueTypeJSON   = "json"
+)
+
+// DateLayout is the text form of date values
+const DateLayout = time.DateOnly
+
+var ErrInvalidValue = fmt.Errorf("invalid element value")
+
+// elementJSON is the wire form of Element: the stored fields plus the typed
+// value. Producers that only send content keep working; their elements are
+// strings, or are parsed from content when they name a value_type.
+type elementJSON struct {
+	elementFields
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type elementFields Element
+
+// elementMsgpack is the msgpack form of Element. The typed columns are
+// hidden from the json tags msgpack keys fields by, so the value travels
+// as its JSON text and is decoded like a JSON value.
+type elementMsgpack struct {
+	elementFields
+	Value string `json:"value,omitempty"`
+}
+
+// SetValue stores value as valueType, filling the typed column and Content
+func (e *Element) SetValue(valueType string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+
+	return e.decodeValue(valueType, raw)
+}
+
+// Value returns the typed value: string, float64, bool, time.Time,
+// []string or json.RawMessage depending on ValueType
+func (e *Element) Value() any {
+	switch e.ValueType {
+	case ValueTypeNumber:
+		if e.NumberValue != nil {
+			return *e.NumberValue
+		}
+	case ValueTypeBool:
+		if e.BoolValue != nil {
+			return *e.BoolValue
+		}
+	case ValueTypeDate:
+		if e.DateValue != nil {
+			return *e.DateValue
+		}
+	case ValueTypeList:
+		return e.ListValue
+	case ValueTypeJSON:
+		return json.RawMessage(e.JSONValue)
+	}
+
+	return e.Content
+}
+
+// valueJSON returns the typed value as JSON, dates in DateLayout
+func (e Element) valueJSON() (json.RawMessage, error) {
+	value := e.Value()
+	if date, ok := value.(time.Time); ok {
+		value = date.Format(DateLayout)
+	}
+
+	return json.Marshal(value)
+}
+
+// Number returns the numeric value of the element, parsing Content for
+// elements stored as strings; false if it isn't a number
+func (e *Element) Number() (float64, bool) {
+	switch value := e.Value().(type) {
+	case float64:
+		return value, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// List returns the chosen options of the element, parsing Content for
+// elements stored as strings; false if it isn't a list
+func (e *Element) List() ([]string, bool) {
+	switch value := e.Value().(type) {
+	case []string:
+		return value, true
+	case string:
+		var list []string
+		err := json.Unmarshal([]byte(value), &list)
+		return list, err == nil
+	default:
+		return nil, false
+	}
+}
+
+func (e Element) MarshalJSON() ([]byte, error) {
+	out := elementJSON{elementFields: elementFields(e)}
+	if out.ValueType == "" {
+		out.ValueType = ValueTypeString
+	}
+
+	var err error
+	if out.Value, err = e.valueJSON(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(out)
+}
+
+// EncodeMsgpack implements msgpack.CustomEncoder, keeping the typed value
+func (e Element) EncodeMsgpack(enc *msgpack.Encoder) error {
+	out := elementMsgpack{elementFields: elementFields(e)}
+	if out.ValueType == "" {
+		out.ValueType = ValueTypeString
+	}
+
+	value, err := e.valueJSON()
+	if err != nil {
+		return err
+	}
+	out.Value = string(value)
+
+	return enc.Encode(&out)
+}
+
+// DecodeMsgpack implements msgpack.CustomDecoder
+func (e *Element) DecodeMsgpack(dec *msgpack.Decoder) error {
+	in := new(elementMsgpack)
+	if err := dec.Decode(in); err != nil {
+		return err
+	}
+
+	*e = Element(in.elementFields)
+
+	if in.Value != "" {
+		return e.decodeValue(e.ValueType, json.RawMessage(in.Value))
+	}
+
+	return e.parseContent()
+}
+
+func (e *Element) UnmarshalJSON(data []byte) error {
+	in := new(elementJSON)
+	if err := json.Unmarshal(data, in); err != nil {
+		return err
+	}
+
+	*e = Element(in.elementFields)
+
+	if len(in.Value) > 0 && !bytes.Equal(in.Value, []byte("null")) {
+		return e.decodeValue(e.ValueType, in.Value)
+	}
+
+	return e.parseContent()
+}
+
+// parseContent fills the typed column from Content, for elements sent
+// without a value
+func (e *Element) parseContent() error {
+	switch e.ValueType {
+	case "", ValueTypeString:
+		e.ValueType = ValueTypeString
+		return nil
+	case ValueTypeNumber, ValueTypeBool, ValueTypeList, ValueTypeJSON:
+		return e.decodeValue(e.ValueType, []byte(e.Content))
+	case ValueTypeDate:
+		raw, _ := json.Marshal(e.Content)
+		return e.decodeValue(e.ValueType, raw)
+	default:
+		return fmt.Errorf("%w: unknown value type %q", ErrInvalidValue, e.ValueType)
+	}
+}
+
+// decodeValue parses a JSON value of valueType into the typed column and
+// sets Content to its text form
+func (e *Element) decodeValue(valueType string, raw json.RawMessage) error {
+	e.ValueType = valueType
+	e.NumberValue, e.BoolValue, e.DateValue, e.ListValue, e.JSONValue = nil, nil, nil, nil, ""
+
+	switch valueType {
+	case "", ValueTypeString:
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("%w: want a string: %v", ErrInvalidValue, err)
+		}
+		e.ValueType = ValueTypeString
+		e.Content = value
+	case ValueTypeNumber:
+		var value float64
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("%w: want a number: %v", ErrInvalidValue, err)
+		}
+		e.NumberValue = &value
+		e.Content = strconv.FormatFloat(value, 'f', -1, 64)
+	case ValueTypeBool:
+		var value bool
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("%w: want a boolean: %v", ErrInvalidValue, err)
+		}
+		e.BoolValue = &value
+		e.Content = strconv.FormatBool(value)
+	case ValueTypeDate:
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return fmt.Errorf("%w: want a date string: %v", ErrInvalidValue, err)
+		}
+		value, err := parseDate(text)
+		if err != nil {
+			return err
+		}
+		e.DateValue = &value
+		e.Content = value.Format(DateLayout)
+	case ValueTypeList:
+		var value []string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("%w: want a list of strings: %v", ErrInvalidValue, err)
+		}
+		if value == nil {
+			value = []string{}
+		}
+		e.ListValue = value
+		content, _ := json.Marshal(value)
+		e.Content = string(content)
+	case ValueTypeJSON:
+		compact := new(bytes.Buffer)
+		if err := json.Compact(compact, raw); err != nil {
+			return fmt.Errorf("%w: want JSON: %v", ErrInvalidValue, err)
+		}
+		e.JSONValue = compact.String()
+		e.Content = compact.String()
+	default:
+		return fmt.Errorf("%w: unknown value type %q", ErrInvalidValue, valueType)
+	}
+
+	return nil
+}
+
+// parseDate accepts a plain date or an RFC 3339 timestamp, keeping the date
+func parseDate(text string) (time.Time, error) {
+	if value, err := time.Parse(DateLayout, text); err == nil {
+		return value, nil
+	}
+
+	value, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is not a date (YYYY-MM-DD)", ErrInvalidValue, text)
+	}
+
+	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC), nil
+}
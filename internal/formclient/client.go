@@ -0,0 +1,72 @@
+// Package formclient reads form definitions from the form service over HTTP.
+// The service uses it for forms missing from its local replica, e.g. forms
+// created before the replica was deployed.
+package formclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// FormPathTemplate is the form service route returning one form definition
+const FormPathTemplate = "/forms/%s"
+
+// Client fetches forms from GET {baseURL}/forms/{id}
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+func New(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// GetForm returns the definition of the form with the given ID
+// Returns entity.ErrFormNotFound when the form service answers 404
+func (c *Client) GetForm(ctx context.Context, id uuid.UUID) (*entity.Form, error) {
+	url := c.baseURL + fmt.Sprintf(FormPathTemplate, id.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build form request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request form %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, entity.ErrFormNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("form service returned %s for form %s", resp.Status, id)
+	}
+
+	form := new(entity.Form)
+	if err := json.NewDecoder(resp.Body).Decode(form); err != nil {
+		return nil, fmt.Errorf("failed to decode form %s: %w", id, err)
+	}
+
+	if form.ID != id {
+		return nil, fmt.Errorf("form service returned form %s for %s", form.ID, id)
+	}
+
+	for i := range form.Questions {
+		form.Questions[i].FormID = form.ID
+	}
+
+	return form, nil
+}
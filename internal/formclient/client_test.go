@@ -0,0 +1,57 @@
+package formclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/formclient"
+	"github.com/google/uuid"
+)
+
+func TestGetForm(t *testing.T) {
+	known := &entity.Form{
+		ID:    uuid.New(),
+		Title: "survey",
+		Questions: []entity.Question{
+			{OrderNumber: 1, Type: entity.QuestionTypeText},
+		},
+	}
+	broken := uuid.New()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/forms/" + known.ID.String():
+			json.NewEncoder(w).Encode(known)
+		case "/forms/" + broken.String():
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := formclient.New(server.URL+"/", time.Second)
+	ctx := context.Background()
+
+	form, err := client.GetForm(ctx, known.ID)
+	if err != nil {
+		t.Fatalf("GetForm: %v", err)
+	}
+	if form.Title != known.Title || len(form.Questions) != 1 || form.Questions[0].FormID != known.ID {
+		t.Fatalf("GetForm = %+v, want %+v with question form IDs set", form, known)
+	}
+
+	if _, err := client.GetForm(ctx, uuid.New()); !errors.Is(err, entity.ErrFormNotFound) {
+		t.Fatalf("GetForm unknown form error = %v, want ErrFormNotFound", err)
+	}
+
+	if _, err := client.GetForm(ctx, broken); err == nil || errors.Is(err, entity.ErrFormNotFound) {
+		t.Fatalf("GetForm on server error = %v, want a non-not-found error", err)
+	}
+}
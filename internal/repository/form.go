@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveForm replaces the stored replica of form and its questions
+// Events can arrive out of order, so a form older than the stored one, or
+// not newer than its deletion, is ignored; the returned bool reports
+// whether form was written.
+func (repo *Repository) SaveForm(ctx context.Context, form *entity.Form) (bool, error) {
+	saved := false
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stored := new(entity.Form)
+
+		res := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", form.ID).Limit(1).Find(stored)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 && stored.UpdatedAt.After(form.UpdatedAt) {
+			return nil
+		}
+		// A tombstone also refuses a redelivered copy of its last version
+		if stored.DeletedAt.Valid && (!form.UpdatedAt.After(stored.DeletedAt.Time) || !form.UpdatedAt.After(stored.UpdatedAt)) {
+			return nil
+		}
+
+		if err := tx.Where("form_id = ?", form.ID).Delete(&entity.Question{}).Error; err != nil {
+			return err
+		}
+
+		form.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Omit("Questions").Save(form).Error; err != nil {
+			return err
+		}
+
+		for i := range form.Questions {
+			form.Questions[i].FormID = form.ID
+		}
+		if len(form.Questions) > 0 {
+			if err := tx.Create(&form.Questions).Error; err != nil {
+				return err
+			}
+		}
+
+		saved = true
+		return nil
+	})
+	if err != nil {
+		repo.logger.Error("error save form",
+			zap.String("form_id", form.ID.String()),
+			zap.Error(err))
+
+		return false, err
+	}
+
+	return saved, nil
+}
+
+// DeleteForm deletes the questions of a form and turns the form into a
+// tombstone dated now, which SaveForm checks. A tombstone is written even
+// for a form that was never stored, in case its events arrive late.
+func (repo *Repository) DeleteForm(ctx context.Context, id uuid.UUID) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("form_id = ?", id).Delete(&entity.Question{}).Error; err != nil {
+			return err
+		}
+
+		deletedAt := gorm.DeletedAt{Time: time.Now().UTC(), Valid: true}
+
+		res := tx.Unscoped().Model(&entity.Form{}).Where("id = ?", id).Update("deleted_at", deletedAt)
+		if res.Error != nil || res.RowsAffected > 0 {
+			return res.Error
+		}
+
+		return tx.Create(&entity.Form{ID: id, DeletedAt: deletedAt}).Error
+	})
+	if err != nil {
+		repo.logger.Error("error delete form",
+			zap.String("form_id", id.String()),
+			zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
+
+// GetForm returns the stored replica of a form with its questions
+// Returns entity.ErrFormDeleted for a tombstone and entity.ErrFormNotFound
+// for a form never stored
+func (repo *Repository) GetForm(ctx context.Context, id uuid.UUID) (*entity.Form, error) {
+	form := new(entity.Form)
+
+	res := repo.db.WithContext(ctx).
+		Unscoped().
+		Preload("Questions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("order_number")
+		}).
+		Where("id = ?", id).
+		First(form)
+
+	if err := res.Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrFormNotFound
+		}
+
+		repo.logger.Error("error get form",
+			zap.String("form_id", id.String()),
+			zap.Error(err))
+
+		return nil, err
+	}
+	if form.DeletedAt.Valid {
+		return nil, entity.ErrFormDeleted
+	}
+
+	return form, nil
+}
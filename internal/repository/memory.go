@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// MemoryRepository keeps answers in process memory
+// It implements the same methods as Repository so tests and local runs
+// don't need a database. Answers are deep-copied on the way in and out,
+// so callers can't mutate stored state.
+type MemoryRepository struct {
+	mu      sync.RWMutex
+	answers map[uuid.UUID]entity.Answer
+	forms   map[uuid.UUID]entity.Form
+	deleted map[uuid.UUID]time.Time
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		answers: make(map[uuid.UUID]entity.Answer),
+		forms:   make(map[uuid.UUID]entity.Form),
+		deleted: make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (repo *MemoryRepository) CreateAnswer(_ context.Context, answer *entity.Answer) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.create(answer)
+}
+
+// CreateAnswerCapped mirrors Repository.CreateAnswerCapped
+func (repo *MemoryRepository) CreateAnswerCapped(_ context.Context, answer *entity.Answer, limit int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	count := 0
+	for _, stored := range repo.answers {
+		if stored.FormID == answer.FormID {
+			count++
+		}
+	}
+	if count >= limit {
+		return entity.ErrFormFull
+	}
+
+	return repo.create(answer)
+}
+
+// create stores answer; the caller must hold the write lock
+func (repo *MemoryRepository) create(answer *entity.Answer) error {
+	if _, ok := repo.answers[answer.ID]; ok && answer.ID != uuid.Nil {
+		return fmt.Errorf("answer %s already exists", answer.ID)
+	}
+
+	if answer.Respondent != nil {
+		for _, stored := range repo.answers {
+			if stored.FormID == answer.FormID && stored.Respondent != nil && *stored.Respondent == *answer.Respondent {
+				return entity.ErrAlreadySubmitted
+			}
+		}
+	}
+
+	if answer.ID == uuid.Nil {
+		answer.ID = uuid.New()
+	}
+
+	now := time.Now()
+	if answer.CreatedAt.IsZero() {
+		answer.CreatedAt = now
+	}
+	answer.UpdatedAt = now
+
+	for i := range answer.Elements {
+		answer.Elements[i].AnswerID = answer.ID
+	}
+
+	stored, err := copyAnswer(answer)
+	if err != nil {
+		return err
+	}
+
+	repo.answers[answer.ID] = *stored
+
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteAnswer(_ context.Context, id uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.answers, id)
+
+	return nil
+}
+
+// UpdateAnswer replaces the stored answer with the same ID, keeping its creation time
+func (repo *MemoryRepository) UpdateAnswer(_ context.Context, answer *entity.Answer) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if stored, ok := repo.answers[answer.ID]; ok {
+		answer.CreatedAt = stored.CreatedAt
+	}
+	answer.UpdatedAt = time.Now()
+
+	for i := range answer.Elements {
+		answer.Elements[i].AnswerID = answer.ID
+	}
+
+	stored, err := copyAnswer(answer)
+	if err != nil {
+		return err
+	}
+
+	repo.answers[answer.ID] = *stored
+
+	return nil
+}
+
+// FindUserAnswer returns the oldest answer of user to form, like Repository.FindUserAnswer
+func (repo *MemoryRepository) FindUserAnswer(_ context.Context, formID, userID uuid.UUID) (*entity.Answer, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var found *entity.Answer
+	for _, answer := range repo.answers {
+		if answer.FormID != formID || answer.UserID != userID {
+			continue
+		}
+		if found == nil || answer.CreatedAt.Before(found.CreatedAt) {
+			found = &answer
+		}
+	}
+
+	if found == nil {
+		return nil, entity.ErrAnswerNotFound
+	}
+
+	return copyAnswer(found)
+}
+
+// DeleteFormAnswers mirrors Repository.DeleteFormAnswers, deleting in ID order
+func (repo *MemoryRepository) DeleteFormAnswers(_ context.Context, formID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var ids []uuid.UUID
+	for id, answer := range repo.answers {
+		if answer.FormID == formID {
+			ids = append(ids, id)
+		}
+	}
+
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	for _, id := range ids {
+		delete(repo.answers, id)
+	}
+
+	return ids, nil
+}
+
+func (repo *MemoryRepository) GetAnswer(_ context.Context, id uuid.UUID) (*entity.Answer, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	answer, ok := repo.answers[id]
+	if !ok {
+		return nil, entity.ErrAnswerNotFound
+	}
+
+	return copyAnswer(&answer)
+}
+
+func (repo *MemoryRepository) CountAnswers(_ context.Context, filter AnswerFilter) (int64, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return int64(len(repo.matching(filter))), nil
+}
+
+// StreamAnswers calls fn with batches of matching answers ordered by ID,
+// mirroring Repository.StreamAnswers
+func (repo *MemoryRepository) StreamAnswers(ctx context.Context, filter AnswerFilter, batchSize int, fn func([]entity.Answer) error) error {
+	repo.mu.RLock()
+	answers := repo.matching(filter)
+	repo.mu.RUnlock()
+
+	for start := 0; start < len(answers); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := min(start+batchSize, len(answers))
+		if err := fn(answers[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matching returns copies of the answers passing filter, ordered by ID
+// The caller must hold the lock
+func (repo *MemoryRepository) matching(filter AnswerFilter) []entity.Answer {
+	out := make([]entity.Answer, 0, len(repo.answers))
+
+	for _, answer := range repo.answers {
+		if len(filter.FormIDs) > 0 && !slices.Contains(filter.FormIDs, answer.FormID) {
+			continue
+		}
+		if !filter.Since.IsZero() && answer.UpdatedAt.Before(filter.Since) {
+			continue
+		}
+
+		copied, err := copyAnswer(&answer)
+		if err != nil {
+			continue
+		}
+		out = append(out, *copied)
+	}
+
+	slices.SortFunc(out, func(a, b entity.Answer) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return out
+}
+
+// SaveForm stores form unless a newer replica or a tombstone at least as
+// new is already stored, mirroring Repository.SaveForm
+func (repo *MemoryRepository) SaveForm(_ context.Context, form *entity.Form) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if stored, ok := repo.forms[form.ID]; ok && stored.UpdatedAt.After(form.UpdatedAt) {
+		return false, nil
+	}
+	if deletedAt, ok := repo.deleted[form.ID]; ok && !form.UpdatedAt.After(deletedAt) {
+		return false, nil
+	}
+
+	copied, err := copyForm(form)
+	if err != nil {
+		return false, err
+	}
+
+	repo.forms[form.ID] = *copied
+	delete(repo.deleted, form.ID)
+
+	return true, nil
+}
+
+// DeleteForm mirrors Repository.DeleteForm, leaving a tombstone dated now
+func (repo *MemoryRepository) DeleteForm(_ context.Context, id uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	deletedAt := time.Now().UTC()
+	if stored, ok := repo.forms[id]; ok && !stored.UpdatedAt.Before(deletedAt) {
+		deletedAt = stored.UpdatedAt
+	}
+
+	delete(repo.forms, id)
+	repo.deleted[id] = deletedAt
+
+	return nil
+}
+
+func (repo *MemoryRepository) GetForm(_ context.Context, id uuid.UUID) (*entity.Form, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	if _, ok := repo.deleted[id]; ok {
+		return nil, entity.ErrFormDeleted
+	}
+
+	form, ok := repo.forms[id]
+	if !ok {
+		return nil, entity.ErrFormNotFound
+	}
+
+	return copyForm(&form)
+}
+
+// copyForm deep-copies a form through its JSON form, restoring the
+// question form IDs the JSON form leaves out
+func copyForm(form *entity.Form) (*entity.Form, error) {
+	data, err := json.Marshal(form)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := new(entity.Form)
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, err
+	}
+
+	for i := range copied.Questions {
+		copied.Questions[i].FormID = copied.ID
+	}
+
+	return copied, nil
+}
+
+// copyAnswer deep-copies an answer through its JSON form, restoring the
+// respondent the JSON form leaves out
+func copyAnswer(answer *entity.Answer) (*entity.Answer, error) {
+	data, err := json.Marshal(answer)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := new(entity.Answer)
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, err
+	}
+
+	if answer.Respondent != nil {
+		respondent := *answer.Respondent
+		copied.Respondent = &respondent
+	}
+
+	return copied, nil
+}
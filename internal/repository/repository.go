@@ -2,14 +2,26 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/Koyo-os/answer-service/internal/entity"
 	"github.com/Koyo-os/answer-service/pkg/logger"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// AnswerFilter narrows the answers read in bulk
+// Zero values mean "no restriction"
+type AnswerFilter struct {
+	FormIDs []uuid.UUID // Only answers to these forms
+	Since   time.Time   // Only answers updated at or after this time
+}
+
 type Repository struct {
 	db     *gorm.DB
 	logger *logger.Logger
@@ -22,10 +34,17 @@ func NewRepository(db *gorm.DB, logger *logger.Logger) *Repository {
 	}
 }
 
+// CreateAnswer stores answer with its elements
+// Returns entity.ErrAlreadySubmitted if answer has a Respondent who already
+// answered the form
 func (repo *Repository) CreateAnswer(ctx context.Context, answer *entity.Answer) error {
 	res := repo.db.WithContext(ctx).Create(answer)
 
 	if err := res.Error; err != nil {
+		if isRespondentConflict(answer, err) {
+			return entity.ErrAlreadySubmitted
+		}
+
 		repo.logger.Error("error create answer",
 			zap.String("answer_id", answer.ID.String()),
 			zap.Error(err))
@@ -49,3 +68,235 @@ func (repo *Repository) DeleteAnswer(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// CreateAnswerCapped stores answer unless its form already has limit answers
+// The form's lock row is locked while counting, so concurrent submissions
+// can't overshoot the cap. The row is upserted first rather than relying on
+// the replica's forms row, which is missing for forms served by a
+// FormProvider. Returns entity.ErrFormFull when the cap is reached, and
+// entity.ErrAlreadySubmitted like CreateAnswer.
+func (repo *Repository) CreateAnswerCapped(ctx context.Context, answer *entity.Answer, limit int) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		lock := &entity.FormLock{FormID: answer.FormID}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(lock).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("form_id = ?", answer.FormID).
+			Take(lock).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&entity.Answer{}).Where("form_id = ?", answer.FormID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(limit) {
+			return entity.ErrFormFull
+		}
+
+		return tx.Create(answer).Error
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, entity.ErrFormFull):
+		return err
+	case isRespondentConflict(answer, err):
+		return entity.ErrAlreadySubmitted
+	}
+
+	repo.logger.Error("error create capped answer",
+		zap.String("answer_id", answer.ID.String()),
+		zap.String("form_id", answer.FormID.String()),
+		zap.Error(err))
+
+	return err
+}
+
+// RespondentIndex is the unique index on (form_id, respondent) that allows
+// one answer per user to a single-response form
+const RespondentIndex = "idx_answers_form_respondent"
+
+// respondentConflicts identify violations of RespondentIndex in driver
+// errors: Postgres and MySQL name the index, SQLite lists its columns
+var respondentConflicts = []string{RespondentIndex, "answers.form_id, answers.respondent"}
+
+// isRespondentConflict reports whether err is the unique respondent index
+// refusing a second answer of the same user, as opposed to e.g. a primary
+// key collision of a redelivered answer
+func isRespondentConflict(answer *entity.Answer, err error) bool {
+	if answer.Respondent == nil || err == nil {
+		return false
+	}
+
+	msg := err.Error()
+	return slices.ContainsFunc(respondentConflicts, func(conflict string) bool {
+		return strings.Contains(msg, conflict)
+	})
+}
+
+// UpdateAnswer replaces the stored answer with the same ID and its elements,
+// keeping its creation time
+func (repo *Repository) UpdateAnswer(ctx context.Context, answer *entity.Answer) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("answer_id = ?", answer.ID).Delete(&entity.Element{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Omit("Elements", "CreatedAt").Save(answer).Error; err != nil {
+			return err
+		}
+
+		for i := range answer.Elements {
+			answer.Elements[i].ID = 0
+			answer.Elements[i].AnswerID = answer.ID
+		}
+		if len(answer.Elements) > 0 {
+			return tx.Create(&answer.Elements).Error
+		}
+
+		return nil
+	})
+	if err != nil {
+		repo.logger.Error("error update answer",
+			zap.String("answer_id", answer.ID.String()),
+			zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
+
+// FindUserAnswer returns the oldest answer of user to form
+// Returns entity.ErrAnswerNotFound if the user hasn't answered it
+func (repo *Repository) FindUserAnswer(ctx context.Context, formID, userID uuid.UUID) (*entity.Answer, error) {
+	answer := new(entity.Answer)
+
+	res := repo.db.WithContext(ctx).
+		Preload("Elements").
+		Where("form_id = ? AND user_id = ?", formID, userID).
+		Order("created_at").
+		First(answer)
+
+	if err := res.Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrAnswerNotFound
+		}
+
+		repo.logger.Error("error find user answer",
+			zap.String("form_id", formID.String()),
+			zap.String("user_id", userID.String()),
+			zap.Error(err))
+
+		return nil, err
+	}
+
+	return answer, nil
+}
+
+// DeleteFormAnswers deletes up to limit answers of the form with the given
+// ID, with their elements, and returns the IDs it deleted
+// Call it until it returns no IDs to remove every answer of the form.
+func (repo *Repository) DeleteFormAnswers(ctx context.Context, formID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Answer{}).
+			Where("form_id = ?", formID).
+			Order("id").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Unscoped().Where("answer_id IN ?", ids).Delete(&entity.Element{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id IN ?", ids).Delete(&entity.Answer{}).Error
+	})
+	if err != nil {
+		repo.logger.Error("error delete form answers",
+			zap.String("form_id", formID.String()),
+			zap.Error(err))
+
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (repo *Repository) GetAnswer(ctx context.Context, id uuid.UUID) (*entity.Answer, error) {
+	answer := new(entity.Answer)
+
+	res := repo.db.WithContext(ctx).Preload("Elements").Where("id = ?", id).First(answer)
+
+	if err := res.Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrAnswerNotFound
+		}
+
+		repo.logger.Error("error get answer",
+			zap.String("answer_id", id.String()),
+			zap.Error(err))
+
+		return nil, err
+	}
+
+	return answer, nil
+}
+
+func (repo *Repository) filtered(ctx context.Context, filter AnswerFilter) *gorm.DB {
+	query := repo.db.WithContext(ctx).Model(&entity.Answer{})
+
+	if len(filter.FormIDs) > 0 {
+		query = query.Where("form_id IN ?", filter.FormIDs)
+	}
+
+	if !filter.Since.IsZero() {
+		query = query.Where("updated_at >= ?", filter.Since)
+	}
+
+	return query
+}
+
+func (repo *Repository) CountAnswers(ctx context.Context, filter AnswerFilter) (int64, error) {
+	var count int64
+
+	if err := repo.filtered(ctx, filter).Count(&count).Error; err != nil {
+		repo.logger.Error("error count answers", zap.Error(err))
+
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// StreamAnswers reads the matching answers with their elements in batches
+// of batchSize, calling fn for each batch. The batch slice is reused, so fn
+// must not keep it after returning.
+func (repo *Repository) StreamAnswers(ctx context.Context, filter AnswerFilter, batchSize int, fn func([]entity.Answer) error) error {
+	var batch []entity.Answer
+
+	res := repo.filtered(ctx, filter).
+		Preload("Elements").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		})
+
+	if err := res.Error; err != nil {
+		repo.logger.Error("error stream answers", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,449 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/database"
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/repository"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// newSQLiteRepository returns a repository backed by a private in-memory SQLite database
+func newSQLiteRepository(t *testing.T) *repository.Repository {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString())
+
+	db, err := database.Open(database.DialectSQLite, dsn)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return repository.NewRepository(db, logger.Get())
+}
+
+func newAnswer(formID uuid.UUID, contents ...string) *entity.Answer {
+	answer := &entity.Answer{
+		ID:     uuid.New(),
+		FormID: formID,
+		UserID: uuid.New(),
+	}
+
+	for i, content := range contents {
+		answer.AddElement(uint(i+1), content)
+	}
+
+	return answer
+}
+
+func TestCreateAndGetAnswer(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	answer := newAnswer(uuid.New(), "yes", "42")
+	if err := repo.CreateAnswer(ctx, answer); err != nil {
+		t.Fatalf("CreateAnswer: %v", err)
+	}
+
+	got, err := repo.GetAnswer(ctx, answer.ID)
+	if err != nil {
+		t.Fatalf("GetAnswer: %v", err)
+	}
+
+	if got.ID != answer.ID || got.FormID != answer.FormID || got.UserID != answer.UserID {
+		t.Fatalf("GetAnswer returned %+v, want ids of %+v", got, answer)
+	}
+
+	if len(got.Elements) != 2 {
+		t.Fatalf("got %d elements, want 2", len(got.Elements))
+	}
+
+	if el := got.GetElementByQuestionOrder(2); el == nil || el.Content != "42" {
+		t.Fatalf("element 2 = %+v, want content 42", el)
+	}
+}
+
+func TestGetAnswerNotFound(t *testing.T) {
+	repo := newSQLiteRepository(t)
+
+	_, err := repo.GetAnswer(context.Background(), uuid.New())
+	if !errors.Is(err, entity.ErrAnswerNotFound) {
+		t.Fatalf("GetAnswer error = %v, want ErrAnswerNotFound", err)
+	}
+}
+
+func TestDeleteAnswer(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	answer := newAnswer(uuid.New(), "a")
+	if err := repo.CreateAnswer(ctx, answer); err != nil {
+		t.Fatalf("CreateAnswer: %v", err)
+	}
+
+	if err := repo.DeleteAnswer(ctx, answer.ID); err != nil {
+		t.Fatalf("DeleteAnswer: %v", err)
+	}
+
+	if _, err := repo.GetAnswer(ctx, answer.ID); !errors.Is(err, entity.ErrAnswerNotFound) {
+		t.Fatalf("GetAnswer after delete error = %v, want ErrAnswerNotFound", err)
+	}
+}
+
+func TestCountAndStreamAnswers(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	formID, otherFormID := uuid.New(), uuid.New()
+
+	for range 5 {
+		if err := repo.CreateAnswer(ctx, newAnswer(formID, "x")); err != nil {
+			t.Fatalf("CreateAnswer: %v", err)
+		}
+	}
+	if err := repo.CreateAnswer(ctx, newAnswer(otherFormID, "y")); err != nil {
+		t.Fatalf("CreateAnswer: %v", err)
+	}
+
+	filter := repository.AnswerFilter{FormIDs: []uuid.UUID{formID}}
+
+	count, err := repo.CountAnswers(ctx, filter)
+	if err != nil {
+		t.Fatalf("CountAnswers: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("CountAnswers = %d, want 5", count)
+	}
+
+	var batches, streamed int
+	err = repo.StreamAnswers(ctx, filter, 2, func(batch []entity.Answer) error {
+		batches++
+		for _, answer := range batch {
+			if answer.FormID != formID {
+				t.Errorf("streamed answer of form %s, want %s", answer.FormID, formID)
+			}
+			if len(answer.Elements) != 1 {
+				t.Errorf("streamed answer has %d elements, want 1", len(answer.Elements))
+			}
+		}
+		streamed += len(batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamAnswers: %v", err)
+	}
+
+	if streamed != 5 || batches != 3 {
+		t.Fatalf("streamed %d answers in %d batches, want 5 in 3", streamed, batches)
+	}
+
+	future := repository.AnswerFilter{Since: time.Now().Add(time.Hour)}
+	if count, err := repo.CountAnswers(ctx, future); err != nil || count != 0 {
+		t.Fatalf("CountAnswers(since future) = %d, %v, want 0", count, err)
+	}
+}
+
+func TestSaveFormKeepsNewestReplica(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	form := &entity.Form{
+		ID:        uuid.New(),
+		Title:     "v2",
+		UpdatedAt: now,
+		Questions: []entity.Question{
+			{OrderNumber: 1, Type: entity.QuestionTypeText, Required: true},
+			{OrderNumber: 2, Type: entity.QuestionTypeSingleChoice, Options: []string{"a", "b"}},
+		},
+	}
+	if saved, err := repo.SaveForm(ctx, form); err != nil || !saved {
+		t.Fatalf("SaveForm = %v, %v, want saved", saved, err)
+	}
+
+	stale := &entity.Form{ID: form.ID, Title: "v1", UpdatedAt: now.Add(-time.Minute)}
+	if saved, err := repo.SaveForm(ctx, stale); err != nil || saved {
+		t.Fatalf("SaveForm(stale) = %v, %v, want ignored", saved, err)
+	}
+
+	got, err := repo.GetForm(ctx, form.ID)
+	if err != nil {
+		t.Fatalf("GetForm: %v", err)
+	}
+	if got.Title != "v2" || len(got.Questions) != 2 {
+		t.Fatalf("GetForm = %+v, want v2 with 2 questions", got)
+	}
+	if q := got.GetQuestion(2); q == nil || len(q.Options) != 2 || q.Options[1] != "b" {
+		t.Fatalf("question 2 = %+v, want options [a b]", q)
+	}
+
+	if err := repo.DeleteForm(ctx, form.ID); err != nil {
+		t.Fatalf("DeleteForm: %v", err)
+	}
+	if _, err := repo.GetForm(ctx, form.ID); !errors.Is(err, entity.ErrFormDeleted) {
+		t.Fatalf("GetForm after delete error = %v, want ErrFormDeleted", err)
+	}
+
+	// A redelivered update must not bring the deleted form back
+	if saved, err := repo.SaveForm(ctx, form); err != nil || saved {
+		t.Fatalf("SaveForm(after delete) = %v, %v, want ignored", saved, err)
+	}
+	if _, err := repo.GetForm(ctx, form.ID); !errors.Is(err, entity.ErrFormDeleted) {
+		t.Fatalf("GetForm after late update error = %v, want ErrFormDeleted", err)
+	}
+
+	restored := &entity.Form{ID: form.ID, Title: "v3", UpdatedAt: time.Now().UTC().Add(time.Hour)}
+	if saved, err := repo.SaveForm(ctx, restored); err != nil || !saved {
+		t.Fatalf("SaveForm(newer than delete) = %v, %v, want saved", saved, err)
+	}
+	if got, err := repo.GetForm(ctx, form.ID); err != nil || got.Title != "v3" {
+		t.Fatalf("GetForm after restore = %+v, %v, want v3", got, err)
+	}
+}
+
+func TestDeleteUnknownFormLeavesTombstone(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	id := uuid.New()
+	if err := repo.DeleteForm(ctx, id); err != nil {
+		t.Fatalf("DeleteForm: %v", err)
+	}
+
+	late := &entity.Form{ID: id, Title: "late", UpdatedAt: time.Now().UTC().Add(-time.Minute)}
+	if saved, err := repo.SaveForm(ctx, late); err != nil || saved {
+		t.Fatalf("SaveForm(late create) = %v, %v, want ignored", saved, err)
+	}
+}
+
+func TestTypedElementValues(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	answer := newAnswer(uuid.New(), "plain")
+
+	values := []struct {
+		order     uint
+		valueType string
+		value     any
+	}{
+		{2, entity.ValueTypeNumber, 4.5},
+		{3, entity.ValueTypeBool, true},
+		{4, entity.ValueTypeDate, "2024-03-01"},
+		{5, entity.ValueTypeList, []string{"a", "b"}},
+		{6, entity.ValueTypeJSON, map[string]int{"x": 1}},
+	}
+
+	for _, v := range values {
+		element := entity.Element{QuestionOrderNumber: v.order}
+		if err := element.SetValue(v.valueType, v.value); err != nil {
+			t.Fatalf("SetValue(%s): %v", v.valueType, err)
+		}
+		answer.Elements = append(answer.Elements, element)
+	}
+
+	if err := repo.CreateAnswer(ctx, answer); err != nil {
+		t.Fatalf("CreateAnswer: %v", err)
+	}
+
+	got, err := repo.GetAnswer(ctx, answer.ID)
+	if err != nil {
+		t.Fatalf("GetAnswer: %v", err)
+	}
+
+	want := map[uint]string{
+		1: `"plain"`,
+		2: `4.5`,
+		3: `true`,
+		4: `"2024-03-01"`,
+		5: `["a","b"]`,
+		6: `{"x":1}`,
+	}
+
+	for order, value := range want {
+		element := got.GetElementByQuestionOrder(order)
+		if element == nil {
+			t.Fatalf("element %d is missing", order)
+		}
+
+		raw, err := json.Marshal(element)
+		if err != nil {
+			t.Fatalf("marshal element %d: %v", order, err)
+		}
+
+		var decoded struct {
+			Value json.RawMessage `json:"value"`
+		}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			t.Fatalf("unmarshal element %d: %v", order, err)
+		}
+
+		if string(decoded.Value) != value {
+			t.Errorf("element %d value = %s, want %s", order, decoded.Value, value)
+		}
+	}
+}
+
+func TestDeleteFormAnswersInBatches(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	formID := uuid.New()
+	for range 5 {
+		if err := repo.CreateAnswer(ctx, newAnswer(formID, "a", "b")); err != nil {
+			t.Fatalf("CreateAnswer: %v", err)
+		}
+	}
+
+	other := newAnswer(uuid.New(), "kept")
+	if err := repo.CreateAnswer(ctx, other); err != nil {
+		t.Fatalf("CreateAnswer: %v", err)
+	}
+
+	var batches []int
+	for {
+		ids, err := repo.DeleteFormAnswers(ctx, formID, 2)
+		if err != nil {
+			t.Fatalf("DeleteFormAnswers: %v", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+		batches = append(batches, len(ids))
+	}
+
+	if fmt.Sprint(batches) != "[2 2 1]" {
+		t.Fatalf("deleted batches %v, want [2 2 1]", batches)
+	}
+
+	count, err := repo.CountAnswers(ctx, repository.AnswerFilter{FormIDs: []uuid.UUID{formID}})
+	if err != nil || count != 0 {
+		t.Fatalf("CountAnswers = %d, %v; want 0", count, err)
+	}
+
+	if _, err := repo.GetAnswer(ctx, other.ID); err != nil {
+		t.Fatalf("answer of another form was deleted: %v", err)
+	}
+}
+
+func TestCreateAnswerRejectsSecondRespondentAnswer(t *testing.T) {
+	repos := []struct {
+		name string
+		repo interface {
+			CreateAnswer(context.Context, *entity.Answer) error
+			FindUserAnswer(context.Context, uuid.UUID, uuid.UUID) (*entity.Answer, error)
+		}
+	}{
+		{"sqlite", newSQLiteRepository(t)},
+		{"memory", repository.NewMemoryRepository()},
+	}
+
+	for _, tt := range repos {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := tt.repo
+			ctx := context.Background()
+
+			formID := uuid.New()
+
+			first := newAnswer(formID, "a")
+			first.Respondent = &first.UserID
+			if err := repo.CreateAnswer(ctx, first); err != nil {
+				t.Fatalf("CreateAnswer: %v", err)
+			}
+
+			second := newAnswer(formID, "b")
+			second.UserID = first.UserID
+			second.Respondent = &second.UserID
+			if err := repo.CreateAnswer(ctx, second); !errors.Is(err, entity.ErrAlreadySubmitted) {
+				t.Fatalf("second CreateAnswer = %v, want ErrAlreadySubmitted", err)
+			}
+
+			// A primary key collision is not a second submission
+			again := newAnswer(uuid.New(), "d")
+			again.ID = first.ID
+			again.Respondent = &again.UserID
+			if err := repo.CreateAnswer(ctx, again); err == nil || errors.Is(err, entity.ErrAlreadySubmitted) {
+				t.Fatalf("CreateAnswer with a taken ID = %v, want a non-ErrAlreadySubmitted error", err)
+			}
+
+			// Answers without a respondent are never unique
+			for range 2 {
+				multiple := newAnswer(formID, "c")
+				multiple.UserID = first.UserID
+				if err := repo.CreateAnswer(ctx, multiple); err != nil {
+					t.Fatalf("CreateAnswer without respondent: %v", err)
+				}
+			}
+
+			// nor do they count against a later answer with one, e.g. sent
+			// before the form became single-response
+			earlier := newAnswer(formID, "e")
+			if err := repo.CreateAnswer(ctx, earlier); err != nil {
+				t.Fatalf("CreateAnswer without respondent: %v", err)
+			}
+			later := newAnswer(formID, "f")
+			later.UserID = earlier.UserID
+			later.Respondent = &later.UserID
+			if err := repo.CreateAnswer(ctx, later); err != nil {
+				t.Fatalf("CreateAnswer after an answer without respondent = %v, want it stored", err)
+			}
+
+			found, err := repo.FindUserAnswer(ctx, formID, first.UserID)
+			if err != nil || found.ID != first.ID {
+				t.Fatalf("FindUserAnswer = %v, %v; want answer %s", found, err, first.ID)
+			}
+		})
+	}
+}
+
+func TestCreateAnswerCapped(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	form := &entity.Form{ID: uuid.New(), MaxResponses: 2, UpdatedAt: time.Now()}
+	if _, err := repo.SaveForm(ctx, form); err != nil {
+		t.Fatalf("SaveForm: %v", err)
+	}
+
+	for i := range 2 {
+		if err := repo.CreateAnswerCapped(ctx, newAnswer(form.ID, "a"), form.MaxResponses); err != nil {
+			t.Fatalf("CreateAnswerCapped #%d: %v", i+1, err)
+		}
+	}
+
+	if err := repo.CreateAnswerCapped(ctx, newAnswer(form.ID, "a"), form.MaxResponses); !errors.Is(err, entity.ErrFormFull) {
+		t.Fatalf("CreateAnswerCapped over the cap = %v, want ErrFormFull", err)
+	}
+
+	count, err := repo.CountAnswers(ctx, repository.AnswerFilter{FormIDs: []uuid.UUID{form.ID}})
+	if err != nil || count != 2 {
+		t.Fatalf("CountAnswers = %d, %v; want 2", count, err)
+	}
+
+	// Forms served by a FormProvider have no replica row to lock
+	unreplicated := uuid.New()
+	if err := repo.CreateAnswerCapped(ctx, newAnswer(unreplicated, "a"), 1); err != nil {
+		t.Fatalf("CreateAnswerCapped(unreplicated form): %v", err)
+	}
+	if err := repo.CreateAnswerCapped(ctx, newAnswer(unreplicated, "a"), 1); !errors.Is(err, entity.ErrFormFull) {
+		t.Fatalf("CreateAnswerCapped(unreplicated form) over the cap = %v, want ErrFormFull", err)
+	}
+}
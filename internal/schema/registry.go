@@ -0,0 +1,105 @@
+// Package schema tracks the payload versions of inbound event types and
+// upgrades payloads of older versions to the current shape, so messages
+// that sat in a queue across a deploy still reach the service intact
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+)
+
+var (
+	ErrUnknownVersion  = errors.New("event schema version is newer than supported")
+	ErrMissingUpcaster = errors.New("no upcaster registered for event schema version")
+)
+
+type (
+	// Upcaster transforms a payload of one version into the next version
+	Upcaster func(payload []byte) ([]byte, error)
+
+	// Registry holds the current payload version of every event type and
+	// the upcasters leading to it, one per version step
+	Registry struct {
+		mu        sync.RWMutex
+		current   map[string]int
+		upcasters map[string]map[int]Upcaster
+	}
+)
+
+func NewRegistry() *Registry {
+	return &Registry{
+		current:   make(map[string]int),
+		upcasters: make(map[string]map[int]Upcaster),
+	}
+}
+
+// SetCurrent declares version as the payload version the service understands for eventType
+func (r *Registry) SetCurrent(eventType string, version int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current[eventType] = version
+}
+
+// AddUpcaster registers fn to upgrade eventType payloads from version from to from+1
+func (r *Registry) AddUpcaster(eventType string, from int, fn Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.upcasters[eventType] == nil {
+		r.upcasters[eventType] = make(map[int]Upcaster)
+	}
+	r.upcasters[eventType][from] = fn
+}
+
+// Current returns the current payload version of eventType
+// Types that were never registered are at entity.InitialSchemaVersion
+func (r *Registry) Current(eventType string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if version, ok := r.current[eventType]; ok {
+		return version
+	}
+	return entity.InitialSchemaVersion
+}
+
+// Upcast rewrites event.Payload step by step until it reaches the current
+// version of event.Type and updates event.SchemaVersion accordingly
+// Events newer than the current version are rejected, since the service
+// can't know what a future shape means
+func (r *Registry) Upcast(event *entity.Event) error {
+	current := r.Current(event.Type)
+	version := event.Version()
+
+	if version > current {
+		return fmt.Errorf("%w: %s v%d (current v%d)", ErrUnknownVersion, event.Type, version, current)
+	}
+
+	r.mu.RLock()
+	steps := r.upcasters[event.Type]
+	r.mu.RUnlock()
+
+	payload := event.Payload
+
+	for ; version < current; version++ {
+		upcast, ok := steps[version]
+		if !ok {
+			return fmt.Errorf("%w: %s v%d", ErrMissingUpcaster, event.Type, version)
+		}
+
+		upgraded, err := upcast(payload)
+		if err != nil {
+			return fmt.Errorf("failed to upcast %s from v%d: %w", event.Type, version, err)
+		}
+		payload = upgraded
+	}
+
+	event.Payload = payload
+	event.SchemaVersion = current
+
+	return nil
+}
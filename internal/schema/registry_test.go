@@ -0,0 +1,62 @@
+package schema_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/schema"
+)
+
+func TestRegistryUpcast(t *testing.T) {
+	appendStep := func(step string) schema.Upcaster {
+		return func(payload []byte) ([]byte, error) {
+			return append(payload, step...), nil
+		}
+	}
+
+	registry := schema.NewRegistry()
+	registry.SetCurrent("chained", 3)
+	registry.AddUpcaster("chained", 1, appendStep("+2"))
+	registry.AddUpcaster("chained", 2, appendStep("+3"))
+	registry.SetCurrent("gap", 3)
+	registry.AddUpcaster("gap", 2, appendStep("+3"))
+
+	tests := []struct {
+		name      string
+		eventType string
+		version   int
+		payload   string
+		err       error
+	}{
+		{name: "every step", eventType: "chained", version: 1, payload: "v1+2+3"},
+		{name: "unversioned is v1", eventType: "chained", version: 0, payload: "v0+2+3"},
+		{name: "last step", eventType: "chained", version: 2, payload: "v2+3"},
+		{name: "current", eventType: "chained", version: 3, payload: "v3"},
+		{name: "newer than current", eventType: "chained", version: 4, err: schema.ErrUnknownVersion},
+		{name: "missing step", eventType: "gap", version: 1, err: schema.ErrMissingUpcaster},
+		{name: "unregistered type", eventType: "other", version: 1, payload: "v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &entity.Event{Type: tt.eventType, SchemaVersion: tt.version, Payload: fmt.Appendf(nil, "v%d", tt.version)}
+
+			err := registry.Upcast(event)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Upcast = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			if string(event.Payload) != tt.payload {
+				t.Fatalf("payload = %q, want %q", event.Payload, tt.payload)
+			}
+			if want := registry.Current(tt.eventType); event.SchemaVersion != want {
+				t.Fatalf("SchemaVersion = %d, want %d", event.SchemaVersion, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// schemas holds one JSON Schema per inbound event type, named <event type>.json,
+// or one per payload shared by several types (see sharedSchemas)
+// Each describes the current payload version; older ones are upcast first
+//
+//go:embed schemas/*.json
+var schemas embed.FS
+
+// sharedSchemas lists the event types validated by a schema file that
+// isn't named after a single event type
+var sharedSchemas = map[string][]string{
+	"form": {"form.created", "form.updated"},
+}
+
+var ErrInvalidPayload = errors.New("invalid event payload")
+
+var printer = message.NewPrinter(language.English)
+
+type (
+	// Validator checks event payloads against the JSON Schema of their type
+	Validator struct {
+		schemas map[string]*jsonschema.Schema
+	}
+
+	// ValidationError lists every invalid field of a rejected payload
+	ValidationError struct {
+		EventType string
+		Fields    []entity.FieldError
+	}
+)
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		parts[i] = field.Field + ": " + field.Message
+	}
+
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidPayload, e.EventType, strings.Join(parts, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidPayload
+}
+
+// NewValidator compiles the embedded schemas
+func NewValidator() (*Validator, error) {
+	entries, err := schemas.ReadDir("schemas")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schemas: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+
+	validator := &Validator{
+		schemas: make(map[string]*jsonschema.Schema, len(entries)),
+	}
+
+	for _, entry := range entries {
+		name := path.Join("schemas", entry.Name())
+
+		raw, err := schemas.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema %s: %w", name, err)
+		}
+
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema %s: %w", name, err)
+		}
+
+		if err := compiler.AddResource(name, doc); err != nil {
+			return nil, fmt.Errorf("failed to add schema %s: %w", name, err)
+		}
+
+		compiled, err := compiler.Compile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile schema %s: %w", name, err)
+		}
+
+		eventTypes, shared := sharedSchemas[strings.TrimSuffix(entry.Name(), ".json")]
+		if !shared {
+			eventTypes = []string{strings.TrimSuffix(entry.Name(), ".json")}
+		}
+
+		for _, eventType := range eventTypes {
+			validator.schemas[eventType] = compiled
+		}
+	}
+
+	return validator, nil
+}
+
+// MustNewValidator is like NewValidator but panics if a schema doesn't compile,
+// which can only happen when an embedded schema file is broken
+func MustNewValidator() *Validator {
+	validator, err := NewValidator()
+	if err != nil {
+		panic(err)
+	}
+	return validator
+}
+
+// Validate checks payload against the schema of eventType
+// Returns a *ValidationError listing the invalid fields, or nil if the payload
+// is valid or the type has no schema
+func (v *Validator) Validate(eventType string, payload []byte) error {
+	compiled, ok := v.schemas[eventType]
+	if !ok {
+		return nil
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return &ValidationError{
+			EventType: eventType,
+			Fields:    []entity.FieldError{{Message: "payload is not valid JSON"}},
+		}
+	}
+
+	err = compiled.Validate(instance)
+	if err == nil {
+		return nil
+	}
+
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+
+	return &ValidationError{
+		EventType: eventType,
+		Fields:    fieldErrors(verr),
+	}
+}
+
+// fieldErrors collects the leaves of the error tree, one per failing keyword,
+// naming missing required properties individually
+func fieldErrors(verr *jsonschema.ValidationError) []entity.FieldError {
+	if len(verr.Causes) > 0 {
+		var fields []entity.FieldError
+		for _, cause := range verr.Causes {
+			fields = append(fields, fieldErrors(cause)...)
+		}
+		return fields
+	}
+
+	location := strings.Join(verr.InstanceLocation, ".")
+
+	switch k := verr.ErrorKind.(type) {
+	case *kind.Required:
+		fields := make([]entity.FieldError, len(k.Missing))
+		for i, missing := range k.Missing {
+			fields[i] = entity.FieldError{
+				Field:   joinPath(location, missing),
+				Message: "is required",
+			}
+		}
+		return fields
+	case *kind.Not:
+		return []entity.FieldError{{Field: location, Message: "value is not allowed"}}
+	}
+
+	return []entity.FieldError{{
+		Field:   location,
+		Message: verr.ErrorKind.LocalizedString(printer),
+	}}
+}
+
+func joinPath(parent, field string) string {
+	if parent == "" {
+		return field
+	}
+	return parent + "." + field
+}
@@ -0,0 +1,99 @@
+package schema_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/schema"
+)
+
+const (
+	formID = "6f1c2a8e-3b4d-4e5f-8a9b-0c1d2e3f4a5b"
+	userID = "7a2b3c4d-5e6f-4a1b-9c8d-7e6f5a4b3c2d"
+)
+
+func TestValidatorFieldPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		fields    []string
+	}{
+		{
+			name:      "valid",
+			eventType: "request.answer.create",
+			payload:   `{"form_id":"` + formID + `","user_id":"` + userID + `","elements":[{"question_order_number":1,"value_type":"string","content":"hi"}]}`,
+		},
+		{
+			name:      "missing required",
+			eventType: "request.answer.create",
+			payload:   `{"elements":[]}`,
+			fields:    []string{"form_id", "user_id"},
+		},
+		{
+			name:      "nil UUID",
+			eventType: "request.answer.create",
+			payload:   `{"form_id":"` + formID + `","user_id":"00000000-0000-0000-0000-000000000000"}`,
+			fields:    []string{"user_id"},
+		},
+		{
+			name:      "nested element",
+			eventType: "request.answer.create",
+			payload:   `{"form_id":"` + formID + `","user_id":"` + userID + `","elements":[{"question_order_number":1,"value_type":"string","content":"hi"},{"question_order_number":-1,"content":"hi"}]}`,
+			fields:    []string{"elements.1.question_order_number", "elements.1.value_type"},
+		},
+		{
+			name:      "neither content nor value",
+			eventType: "request.answer.create",
+			payload:   `{"form_id":"` + formID + `","user_id":"` + userID + `","elements":[{"question_order_number":1,"value_type":"string"}]}`,
+			fields:    []string{"elements.0.content", "elements.0.value"},
+		},
+		{
+			name:      "shared form schema",
+			eventType: "form.updated",
+			payload:   `{"id":"not-a-uuid","questions":[]}`,
+			fields:    []string{"id"},
+		},
+		{
+			name:      "not JSON",
+			eventType: "form.deleted",
+			payload:   `{"id":`,
+			fields:    []string{""},
+		},
+		{
+			name:      "type without schema",
+			eventType: "answer.created",
+			payload:   `{"anything":true}`,
+		},
+	}
+
+	validator := schema.MustNewValidator()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.Validate(tt.eventType, []byte(tt.payload))
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+
+			var verr *schema.ValidationError
+			if !errors.As(err, &verr) || !errors.Is(err, schema.ErrInvalidPayload) {
+				t.Fatalf("Validate = %v, want a *ValidationError", err)
+			}
+
+			fields := make([]string, len(verr.Fields))
+			for i, field := range verr.Fields {
+				fields[i] = field.Field
+			}
+			slices.Sort(fields)
+
+			if !slices.Equal(fields, tt.fields) {
+				t.Fatalf("fields = %q, want %q (%v)", fields, tt.fields, err)
+			}
+		})
+	}
+}
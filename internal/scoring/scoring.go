@@ -0,0 +1,105 @@
+// Package scoring grades complete answers to quiz forms
+package scoring
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/validation"
+)
+
+// Score grades answer against the correct answers of form, setting its
+// score and the correctness and points of each graded element
+// Only complete answers to quiz forms are graded; any grading sent with
+// other answers is cleared. Questions hidden by skip logic don't count
+// towards the maximum score.
+func Score(form *entity.Form, answer *entity.Answer) {
+	reset(answer)
+
+	if !answer.IsComplete || !form.IsQuiz() {
+		return
+	}
+
+	var score, maxScore float64
+
+	visibility := validation.NewVisibility(form, answer)
+
+	for i := range form.Questions {
+		question := &form.Questions[i]
+		if !question.Scored() || !visibility.IsVisible(question.OrderNumber) {
+			continue
+		}
+
+		worth := question.Worth()
+		maxScore += worth
+
+		element := answer.GetElementByQuestionOrder(question.OrderNumber)
+		if element == nil {
+			continue
+		}
+
+		correct := IsCorrect(question, element)
+
+		points := 0.0
+		if correct {
+			points = worth
+		}
+
+		element.Correct = &correct
+		element.Points = &points
+		score += points
+	}
+
+	answer.Score = &score
+	answer.MaxScore = &maxScore
+}
+
+// IsCorrect reports whether element is one of the correct answers of question
+// Numbers and ratings compare by value, text and emails ignore case and
+// surrounding space, and multiple choice must pick exactly the correct options.
+func IsCorrect(question *entity.Question, element *entity.Element) bool {
+	content := strings.TrimSpace(element.Content)
+
+	switch question.Type {
+	case entity.QuestionTypeMultipleChoice:
+		chosen, ok := element.List()
+		if !ok {
+			return false
+		}
+		return sameOptions(chosen, question.Correct)
+	case entity.QuestionTypeNumber, entity.QuestionTypeRating:
+		value, ok := element.Number()
+		if !ok {
+			return false
+		}
+		return slices.ContainsFunc(question.Correct, func(correct string) bool {
+			want, err := strconv.ParseFloat(strings.TrimSpace(correct), 64)
+			return err == nil && want == value
+		})
+	case entity.QuestionTypeText, entity.QuestionTypeEmail:
+		return slices.ContainsFunc(question.Correct, func(correct string) bool {
+			return strings.EqualFold(strings.TrimSpace(correct), content)
+		})
+	default:
+		return slices.Contains(question.Correct, content)
+	}
+}
+
+func sameOptions(chosen, correct []string) bool {
+	chosen, correct = slices.Clone(chosen), slices.Clone(correct)
+	slices.Sort(chosen)
+	slices.Sort(correct)
+
+	return slices.Equal(slices.Compact(chosen), slices.Compact(correct))
+}
+
+func reset(answer *entity.Answer) {
+	answer.Score, answer.MaxScore = nil, nil
+
+	for i := range answer.Elements {
+		answer.Elements[i].Correct = nil
+		answer.Elements[i].Points = nil
+	}
+}
@@ -0,0 +1,197 @@
+package scoring_test
+
+import (
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/scoring"
+	"github.com/google/uuid"
+)
+
+func newQuiz() *entity.Form {
+	return &entity.Form{
+		ID: uuid.New(),
+		Questions: []entity.Question{
+			{OrderNumber: 1, Type: entity.QuestionTypeNumber, Correct: []string{"4"}, Points: 2},
+			{OrderNumber: 2, Type: entity.QuestionTypeSingleChoice, Options: []string{"a", "b"}, Correct: []string{"b"}},
+			{OrderNumber: 3, Type: entity.QuestionTypeText},
+			{
+				OrderNumber: 4,
+				Type:        entity.QuestionTypeText,
+				Correct:     []string{"yes"},
+				VisibleIf: &entity.VisibilityRule{Conditions: []entity.Condition{
+					{Question: 2, Operator: entity.ConditionEquals, Value: "a"},
+				}},
+			},
+		},
+	}
+}
+
+func newAnswer(form *entity.Form, complete bool, contents map[uint]string) *entity.Answer {
+	answer := &entity.Answer{
+		ID:         uuid.New(),
+		FormID:     form.ID,
+		UserID:     uuid.New(),
+		IsComplete: complete,
+	}
+
+	for order := uint(1); order <= 4; order++ {
+		if content, ok := contents[order]; ok {
+			answer.AddElement(order, content)
+		}
+	}
+
+	return answer
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		form     *entity.Form
+		complete bool
+		contents map[uint]string
+		score    *float64
+		maxScore float64
+		correct  map[uint]bool
+		ungraded []uint
+	}{
+		{
+			name:     "all correct, hidden question left out",
+			form:     newQuiz(),
+			complete: true,
+			contents: map[uint]string{1: "4", 2: "b", 3: "anything"},
+			score:    ptr(3),
+			maxScore: 3,
+			correct:  map[uint]bool{1: true, 2: true},
+			ungraded: []uint{3},
+		},
+		{
+			name:     "branch shown, wrong answers",
+			form:     newQuiz(),
+			complete: true,
+			contents: map[uint]string{1: "4.0", 2: "a", 4: "no"},
+			score:    ptr(2),
+			maxScore: 4,
+			correct:  map[uint]bool{1: true, 2: false, 4: false},
+		},
+		{
+			name:     "unanswered question still counts",
+			form:     newQuiz(),
+			complete: true,
+			contents: map[uint]string{2: "b"},
+			score:    ptr(1),
+			maxScore: 3,
+			correct:  map[uint]bool{2: true},
+		},
+		{
+			name:     "incomplete answer",
+			form:     newQuiz(),
+			contents: map[uint]string{1: "4", 2: "b"},
+			ungraded: []uint{1, 2},
+		},
+		{
+			name: "not a quiz",
+			form: &entity.Form{Questions: []entity.Question{
+				{OrderNumber: 1, Type: entity.QuestionTypeText},
+			}},
+			complete: true,
+			contents: map[uint]string{1: "x"},
+			ungraded: []uint{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer := newAnswer(tt.form, tt.complete, tt.contents)
+
+			// Grading sent by the client must never survive
+			stale := true
+			for i := range answer.Elements {
+				answer.Elements[i].Correct = &stale
+			}
+
+			scoring.Score(tt.form, answer)
+
+			if tt.score == nil {
+				if answer.Score != nil || answer.MaxScore != nil {
+					t.Fatalf("Score = %v / %v, want ungraded", answer.Score, answer.MaxScore)
+				}
+			} else {
+				if answer.Score == nil || *answer.Score != *tt.score {
+					t.Fatalf("Score = %v, want %v", answer.Score, *tt.score)
+				}
+				if answer.MaxScore == nil || *answer.MaxScore != tt.maxScore {
+					t.Fatalf("MaxScore = %v, want %v", answer.MaxScore, tt.maxScore)
+				}
+			}
+
+			for order, want := range tt.correct {
+				element := answer.GetElementByQuestionOrder(order)
+				if element.Correct == nil || *element.Correct != want {
+					t.Errorf("question %d correct = %v, want %v", order, element.Correct, want)
+				}
+				if element.Points == nil || (*element.Points > 0) != want {
+					t.Errorf("question %d points = %v, want earned %v", order, element.Points, want)
+				}
+			}
+
+			for _, order := range tt.ungraded {
+				element := answer.GetElementByQuestionOrder(order)
+				if element.Correct != nil || element.Points != nil {
+					t.Errorf("question %d graded %v / %v, want ungraded", order, element.Correct, element.Points)
+				}
+			}
+		})
+	}
+}
+
+// text is an element sent as a string, as before typed values
+func text(content string) entity.Element {
+	return entity.Element{ValueType: entity.ValueTypeString, Content: content}
+}
+
+func typed(valueType string, value any) entity.Element {
+	element := entity.Element{}
+	if err := element.SetValue(valueType, value); err != nil {
+		panic(err)
+	}
+	return element
+}
+
+func TestIsCorrect(t *testing.T) {
+	tests := []struct {
+		name     string
+		question entity.Question
+		element  entity.Element
+		want     bool
+	}{
+		{"number same value", entity.Question{Type: entity.QuestionTypeNumber, Correct: []string{"4"}}, text("4.0"), true},
+		{"number other value", entity.Question{Type: entity.QuestionTypeNumber, Correct: []string{"4"}}, text("5"), false},
+		{"number not a number", entity.Question{Type: entity.QuestionTypeNumber, Correct: []string{"4"}}, text("four"), false},
+		{"number typed", entity.Question{Type: entity.QuestionTypeNumber, Correct: []string{"4.5"}}, typed(entity.ValueTypeNumber, 4.5), true},
+		{"rating any of several", entity.Question{Type: entity.QuestionTypeRating, Correct: []string{"4", " 5 "}}, text("5"), true},
+		{"text ignores case and space", entity.Question{Type: entity.QuestionTypeText, Correct: []string{"Paris"}}, text("  paris "), true},
+		{"text other word", entity.Question{Type: entity.QuestionTypeText, Correct: []string{"Paris"}}, text("London"), false},
+		{"email ignores case", entity.Question{Type: entity.QuestionTypeEmail, Correct: []string{"a@b.com"}}, text("A@B.com"), true},
+		{"single choice is exact", entity.Question{Type: entity.QuestionTypeSingleChoice, Correct: []string{"a"}}, text("A"), false},
+		{"single choice match", entity.Question{Type: entity.QuestionTypeSingleChoice, Correct: []string{"a"}}, text("a"), true},
+		{"multiple choice same set", entity.Question{Type: entity.QuestionTypeMultipleChoice, Correct: []string{"a", "b"}}, text(`["b","a"]`), true},
+		{"multiple choice typed", entity.Question{Type: entity.QuestionTypeMultipleChoice, Correct: []string{"a", "b"}}, typed(entity.ValueTypeList, []string{"b", "a"}), true},
+		{"multiple choice duplicates", entity.Question{Type: entity.QuestionTypeMultipleChoice, Correct: []string{"a", "b"}}, text(`["a","b","a"]`), true},
+		{"multiple choice subset", entity.Question{Type: entity.QuestionTypeMultipleChoice, Correct: []string{"a", "b"}}, text(`["a"]`), false},
+		{"multiple choice superset", entity.Question{Type: entity.QuestionTypeMultipleChoice, Correct: []string{"a", "b"}}, text(`["a","b","c"]`), false},
+		{"multiple choice not a list", entity.Question{Type: entity.QuestionTypeMultipleChoice, Correct: []string{"a"}}, text("a"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoring.IsCorrect(&tt.question, &tt.element); got != tt.want {
+				t.Fatalf("IsCorrect(%q) = %v, want %v", tt.element.Content, got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
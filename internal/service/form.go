@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/validation"
+	"github.com/Koyo-os/answer-service/pkg/retrier"
+	"github.com/google/uuid"
+)
+
+const FormKeyTemplate = "form:%s"
+
+// DefaultBulkDeleteBatchSize is how many answers of a deleted form are
+// removed per transaction
+const DefaultBulkDeleteBatchSize = 500
+
+// SetFormProvider sets where forms missing from the local replica are read
+// from, e.g. the form service. Forms it returns are stored in the replica,
+// which backfills forms created before the replica was fed.
+func (s *Service) SetFormProvider(provider FormProvider) {
+	s.provider = provider
+}
+
+// SetRequireKnownForms makes the service reject answers to forms found
+// neither in the replica nor through the provider. By default such answers
+// are stored without being checked against their form.
+func (s *Service) SetRequireKnownForms(require bool) {
+	s.requireKnown = require
+}
+
+// SaveForm stores the replica of a created or updated form, refreshes
+// its cached copy and drops its cached statistics. Stale updates (older
+// than the stored replica) are ignored.
+func (s *Service) SaveForm(form *entity.Form) error {
+	if err := form.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.getContext()
+	defer cancel()
+
+	saved, err := s.forms.SaveForm(ctx, form)
+	if err != nil {
+		return fmt.Errorf("failed to save form: %w", err)
+	}
+	if !saved {
+		return nil
+	}
+
+	return s.executeAsyncOperations(
+		func() error {
+			return retrier.Do(DefaultRetrierAttempts, DefaultRetryDelay, func() error {
+				return s.casher.DoCashing(ctx, fmt.Sprintf(FormKeyTemplate, form.ID.String()), form)
+			})
+		},
+		s.createStatsInvalidateOperation(form.ID),
+	)
+}
+
+// DeleteForm removes the replica of a deleted form and its cached copy,
+// then every answer to the form (see DeleteFormAnswers)
+func (s *Service) DeleteForm(id string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidID, id)
+	}
+
+	ctx, cancel := s.getContext()
+	defer cancel()
+
+	if err := s.forms.DeleteForm(ctx, uid); err != nil {
+		return fmt.Errorf("failed to delete form: %w", err)
+	}
+
+	if err := retrier.Do(DefaultRetrierAttempts, DefaultRetryDelay, func() error {
+		return s.casher.DeleteFromCash(ctx, fmt.Sprintf(FormKeyTemplate, id))
+	}); err != nil {
+		return err
+	}
+
+	if _, err := s.DeleteFormAnswers(uid); err != nil {
+		return err
+	}
+
+	return s.createStatsInvalidateOperation(uid)()
+}
+
+// DeleteFormAnswers removes the answers to a form in batches, dropping
+// their cached copies, and publishes one answer.bulk_deleted event for all
+// of them instead of an answer.deleted event each. Returns how many answers
+// were deleted; nothing is published when there were none.
+// The form replica is turned into a tombstone first, so new answers to it
+// are rejected as form_deleted while this runs, and a redelivered form.deleted event resumes where a
+// failed run stopped. The resumed run only counts and announces the answers
+// it deleted itself, so consumers must not treat the count as the form's
+// total, and nothing is published if the failed run had deleted them all.
+func (s *Service) DeleteFormAnswers(formID uuid.UUID) (int, error) {
+	deleted := 0
+
+	for {
+		ids, err := s.deleteAnswerBatch(formID)
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += len(ids)
+
+		if len(ids) < DefaultBulkDeleteBatchSize {
+			break
+		}
+	}
+
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	payload := &BulkDeletePayload{
+		FormID:    formID.String(),
+		Count:     deleted,
+		DeletedAt: time.Now().UTC(),
+	}
+
+	if err := s.createPublishOperation(payload, AnswerBulkDeletedEventType)(); err != nil {
+		return deleted, fmt.Errorf("failed to publish bulk deletion: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// deleteAnswerBatch deletes one batch of answers to a form and their cache entries
+func (s *Service) deleteAnswerBatch(formID uuid.UUID) ([]uuid.UUID, error) {
+	ctx, cancel := s.getContext()
+	defer cancel()
+
+	ids, err := s.repository.DeleteFormAnswers(ctx, formID, DefaultBulkDeleteBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete answers of form %s: %w", formID, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf(AnswerKeyTemplate, id.String())
+	}
+
+	if err := retrier.Do(DefaultRetrierAttempts, DefaultRetryDelay, func() error {
+		return s.casher.MarkDeleted(ctx, keys)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to drop cached answers of form %s: %w", formID, err)
+	}
+
+	return ids, nil
+}
+
+// GetForm returns the replica of the form with the given ID, reading through the cache
+// Returns entity.ErrFormNotFound if the form is unknown and entity.ErrFormDeleted
+// if it was deleted; deleted forms aren't cached
+func (s *Service) GetForm(ctx context.Context, id uuid.UUID) (*entity.Form, error) {
+	form := new(entity.Form)
+
+	found, err := s.casher.Fetch(ctx, fmt.Sprintf(FormKeyTemplate, id.String()), form, func(ctx context.Context) (any, error) {
+		loaded, err := s.forms.GetForm(ctx, id)
+		if errors.Is(err, entity.ErrFormNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return loaded, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form: %w", err)
+	}
+	if !found {
+		return nil, entity.ErrFormNotFound
+	}
+
+	// Question form IDs aren't part of the cached JSON
+	for i := range form.Questions {
+		form.Questions[i].FormID = form.ID
+	}
+
+	return form, nil
+}
+
+// formFor returns the definition of the form with the given ID from the
+// local replica, falling back to the configured provider
+func (s *Service) formFor(ctx context.Context, id uuid.UUID) (*entity.Form, error) {
+	form, err := s.GetForm(ctx, id)
+	if !errors.Is(err, entity.ErrFormNotFound) || s.provider == nil {
+		return form, err
+	}
+
+	form, err = s.provider.GetForm(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed backfill is retried by the next lookup of the form
+	_ = s.SaveForm(form)
+
+	// The provider may still serve a form whose deletion was applied here
+	if _, err := s.forms.GetForm(ctx, id); errors.Is(err, entity.ErrFormDeleted) {
+		return nil, err
+	}
+
+	return form, nil
+}
+
+// checkAnswer validates answer against the questions of its form and returns the form
+// Returns an *entity.RejectError when the answer doesn't fit its form, when
+// the form was deleted, or when the form is unknown and known forms are
+// required (see SetRequireKnownForms)
+func (s *Service) checkAnswer(ctx context.Context, answer *entity.Answer) (*entity.Form, error) {
+	form, err := s.formFor(ctx, answer.FormID)
+	if errors.Is(err, entity.ErrFormDeleted) {
+		return nil, &entity.RejectError{
+			Reason: entity.RejectReasonFormDeleted,
+			Fields: []entity.FieldError{{
+				Field:   "form_id",
+				Message: fmt.Sprintf("form %s was deleted", answer.FormID),
+			}},
+			Err: err,
+		}
+	}
+	if errors.Is(err, entity.ErrFormNotFound) && !s.requireKnown {
+		// Nothing to check against, so the answer is stored as is
+		return &entity.Form{ID: answer.FormID}, nil
+	}
+	if errors.Is(err, entity.ErrFormNotFound) {
+		return nil, &entity.RejectError{
+			Reason: entity.RejectReasonFormNotFound,
+			Fields: []entity.FieldError{{
+				Field:   "form_id",
+				Message: fmt.Sprintf("form %s does not exist", answer.FormID),
+			}},
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load form: %w", err)
+	}
+
+	if err := validation.Validate(form, answer); err != nil {
+		return nil, err
+	}
+
+	return form, nil
+}
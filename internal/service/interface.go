@@ -4,13 +4,36 @@ import (
 	"context"
 
 	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/repository"
 	"github.com/google/uuid"
 )
 
 type (
 	Repository interface {
 		CreateAnswer(context.Context, *entity.Answer) error
+		// CreateAnswerCapped returns entity.ErrFormFull if the form already has limit answers
+		CreateAnswerCapped(ctx context.Context, answer *entity.Answer, limit int) error
 		DeleteAnswer(context.Context, uuid.UUID) error
+		GetAnswer(context.Context, uuid.UUID) (*entity.Answer, error)
+		UpdateAnswer(context.Context, *entity.Answer) error
+		// FindUserAnswer returns entity.ErrAnswerNotFound if the user hasn't answered the form
+		FindUserAnswer(ctx context.Context, formID, userID uuid.UUID) (*entity.Answer, error)
+		// DeleteFormAnswers deletes up to limit answers of a form, returning their IDs
+		DeleteFormAnswers(context.Context, uuid.UUID, int) ([]uuid.UUID, error)
+		StreamAnswers(context.Context, repository.AnswerFilter, int, func([]entity.Answer) error) error
+	}
+
+	// FormRepository stores the local replica of the form service's forms
+	FormRepository interface {
+		SaveForm(context.Context, *entity.Form) (bool, error)
+		DeleteForm(context.Context, uuid.UUID) error
+		GetForm(context.Context, uuid.UUID) (*entity.Form, error)
+	}
+
+	// FormProvider supplies the definitions of forms missing from the local
+	// replica (see Service.SetFormProvider)
+	FormProvider interface {
+		GetForm(context.Context, uuid.UUID) (*entity.Form, error)
 	}
 
 	Publisher interface {
@@ -20,5 +43,12 @@ type (
 	Casher interface {
 		DoCashing(context.Context, string, any) error // payload must to be pointer
 		DeleteFromCash(context.Context, string) error
+		// MarkDeleted caches "not found" for keys so a bulk refill can't bring them back
+		MarkDeleted(context.Context, []string) error
+		// Version and BumpVersion read and increment a counter used to retire derived entries
+		Version(context.Context, string) (int64, error)
+		BumpVersion(context.Context, string) (int64, error)
+		// Fetch reads through the cache, loading on a miss; the loader returns nil when nothing exists
+		Fetch(context.Context, string, any, func(context.Context) (any, error)) (bool, error)
 	}
 )
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/scoring"
 	"github.com/Koyo-os/answer-service/pkg/retrier"
 	"github.com/google/uuid"
 )
@@ -19,31 +20,48 @@ const (
 )
 
 const (
-	AnswerCreatedEventType = "answer.created"
-	AnswerDeletedEventType = "answer.deleted"
+	AnswerCreatedEventType     = "answer.created"
+	AnswerUpdatedEventType     = "answer.updated"
+	AnswerCompletedEventType   = "answer.completed"
+	AnswerDeletedEventType     = "answer.deleted"
+	AnswerRejectedEventType    = "answer.rejected"
+	AnswerBulkDeletedEventType = "answer.bulk_deleted"
 )
 
 var (
-	ErrAnswerNil = errors.New("answer cannot be nil")
-	ErrInvalidID = errors.New("invalid answer ID format")
+	ErrAnswerNil = entity.ErrAnswerNil
+	ErrInvalidID = entity.ErrInvalidID
 )
 
 type Service struct {
-	casher     Casher
-	publisher  Publisher
-	repository Repository
-	timeout    time.Duration
+	casher       Casher
+	publisher    Publisher
+	repository   Repository
+	forms        FormRepository
+	provider     FormProvider
+	requireKnown bool
+	timeout      time.Duration
 }
 
 type DeletePayload struct {
 	ID string `json:"id"`
 }
 
-func NewService(casher Casher, publisher Publisher, repo Repository, timeout time.Duration) *Service {
+// BulkDeletePayload summarises the answers removed together with their form
+// Count only covers the run that published the event: when a failed run is
+// resumed, the answers it had already deleted are not counted again.
+type BulkDeletePayload struct {
+	FormID    string    `json:"form_id"`
+	Count     int       `json:"count"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func NewService(casher Casher, publisher Publisher, repo Repository, forms FormRepository, timeout time.Duration) *Service {
 	return &Service{
 		casher:     casher,
 		publisher:  publisher,
 		repository: repo,
+		forms:      forms,
 		timeout:    timeout,
 	}
 }
@@ -60,21 +78,192 @@ func (s *Service) Add(answer *entity.Answer) error {
 	ctx, cancel := s.getContext()
 	defer cancel()
 
-	if err := s.repository.CreateAnswer(ctx, answer); err != nil {
+	form, err := s.checkAnswer(ctx, answer)
+	if err != nil {
+		return err
+	}
+
+	if err := form.CheckOpen(time.Now()); err != nil {
+		return notAccepting(form, err)
+	}
+
+	scoring.Score(form, answer)
+
+	if form.SingleResponse() {
+		return s.addSingle(ctx, form, answer)
+	}
+
+	return s.create(ctx, form, answer)
+}
+
+// addSingle stores the only answer a user may give to a single-response form
+// A second submission is rejected with entity.ErrAlreadySubmitted, or
+// replaces the first one if the form lets users edit their answer.
+func (s *Service) addSingle(ctx context.Context, form *entity.Form, answer *entity.Answer) error {
+	respondent := answer.UserID
+	answer.Respondent = &respondent
+
+	existing, err := s.repository.FindUserAnswer(ctx, answer.FormID, answer.UserID)
+	switch {
+	case errors.Is(err, entity.ErrAnswerNotFound):
+		err = s.create(ctx, form, answer)
+		if !errors.Is(err, entity.ErrAlreadySubmitted) {
+			return err
+		}
+
+		// Another submission of the same user was stored first
+		existing, err = s.repository.FindUserAnswer(ctx, answer.FormID, answer.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to find answer of user: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to find answer of user: %w", err)
+	}
+
+	// A redelivered request finds the answer it stored the first time
+	if existing.ID == answer.ID {
+		return nil
+	}
+
+	if form.ResponsePolicy != entity.ResponsePolicySingleEditable {
+		return alreadySubmitted(existing)
+	}
+
+	answer.ID = existing.ID
+	answer.CreatedAt = existing.CreatedAt
+
+	return s.update(ctx, answer)
+}
+
+// alreadySubmitted rejects a second answer to a single-response form
+func alreadySubmitted(existing *entity.Answer) error {
+	return &entity.RejectError{
+		Reason: entity.RejectReasonAlreadySubmitted,
+		Fields: []entity.FieldError{{
+			Field:   "user_id",
+			Message: fmt.Sprintf("user %s has already answered form %s (answer %s)", existing.UserID, existing.FormID, existing.ID),
+		}},
+		Err: entity.ErrAlreadySubmitted,
+	}
+}
+
+// notAccepting rejects an answer to a form that is closed or full
+func notAccepting(form *entity.Form, err error) error {
+	reason := entity.RejectReasonFormClosed
+	switch {
+	case errors.Is(err, entity.ErrFormNotOpen):
+		reason = entity.RejectReasonFormNotOpen
+	case errors.Is(err, entity.ErrFormFull):
+		reason = entity.RejectReasonFormFull
+	}
+
+	return &entity.RejectError{
+		Reason: reason,
+		Fields: []entity.FieldError{{
+			Field:   "form_id",
+			Message: fmt.Sprintf("form %s: %s", form.ID, err),
+		}},
+		Err: err,
+	}
+}
+
+// create stores a new answer to form, enforcing the form's response cap
+// A redelivered request whose answer was stored the first time succeeds
+// without storing or publishing it again.
+func (s *Service) create(ctx context.Context, form *entity.Form, answer *entity.Answer) error {
+	var err error
+	if form.MaxResponses > 0 {
+		err = s.repository.CreateAnswerCapped(ctx, answer, form.MaxResponses)
+	} else {
+		err = s.repository.CreateAnswer(ctx, answer)
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, entity.ErrAlreadySubmitted):
+		return err
+	case s.isStored(ctx, answer.ID):
+		return nil
+	case errors.Is(err, entity.ErrFormFull):
+		return notAccepting(form, err)
+	default:
 		return fmt.Errorf("failed to create answer: %w", err)
 	}
 
-	// Execute cache and publish operations concurrently
-	if err := s.executeAsyncOperations(
+	return s.stored(answer, AnswerCreatedEventType)
+}
+
+// isStored reports whether an answer with the given ID already exists
+func (s *Service) isStored(ctx context.Context, id uuid.UUID) bool {
+	if id == uuid.Nil {
+		return false
+	}
+
+	_, err := s.repository.GetAnswer(ctx, id)
+	return err == nil
+}
+
+func (s *Service) update(ctx context.Context, answer *entity.Answer) error {
+	if err := s.repository.UpdateAnswer(ctx, answer); err != nil {
+		return fmt.Errorf("failed to update answer: %w", err)
+	}
+
+	return s.stored(answer, AnswerUpdatedEventType)
+}
+
+// stored caches a stored answer and publishes eventType for it, plus
+// answer.completed (carrying the quiz score, if any) when it is complete
+func (s *Service) stored(answer *entity.Answer, eventType string) error {
+	operations := []func() error{
 		s.createCacheOperation(answer),
-		s.createPublishOperation(answer, AnswerCreatedEventType),
-	); err != nil {
+		s.createStatsInvalidateOperation(answer.FormID),
+		s.createPublishOperation(answer, eventType),
+	}
+	if answer.IsComplete {
+		operations = append(operations, s.createPublishOperation(answer, AnswerCompletedEventType))
+	}
+
+	// Execute cache and publish operations concurrently
+	if err := s.executeAsyncOperations(operations...); err != nil {
 		return fmt.Errorf("failed to complete async operations: %w", err)
 	}
 
 	return nil
 }
 
+// Get returns the answer with the given ID, reading through the cache
+// Returns entity.ErrAnswerNotFound if no such answer exists
+func (s *Service) Get(id string) (*entity.Answer, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidID, id)
+	}
+
+	ctx, cancel := s.getContext()
+	defer cancel()
+
+	answer := new(entity.Answer)
+
+	found, err := s.casher.Fetch(ctx, fmt.Sprintf(AnswerKeyTemplate, id), answer, func(ctx context.Context) (any, error) {
+		loaded, err := s.repository.GetAnswer(ctx, uid)
+		if errors.Is(err, entity.ErrAnswerNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return loaded, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get answer: %w", err)
+	}
+	if !found {
+		return nil, entity.ErrAnswerNotFound
+	}
+
+	return answer, nil
+}
+
 func (s *Service) Delete(id string) error {
 	uid, err := uuid.Parse(id)
 	if err != nil {
@@ -84,23 +273,44 @@ func (s *Service) Delete(id string) error {
 	ctx, cancel := s.getContext()
 	defer cancel()
 
+	// The form ID is only needed to drop the form's cached statistics
+	existing, err := s.repository.GetAnswer(ctx, uid)
+	if err != nil && !errors.Is(err, entity.ErrAnswerNotFound) {
+		return fmt.Errorf("failed to get answer: %w", err)
+	}
+
 	if err := s.repository.DeleteAnswer(ctx, uid); err != nil {
 		return fmt.Errorf("failed to delete answer: %w", err)
 	}
 
 	deletePayload := &DeletePayload{ID: id}
 
-	// Execute cache deletion and publish operations concurrently
-	if err := s.executeAsyncOperations(
+	operations := []func() error{
 		s.createCacheDeleteOperation(id),
 		s.createPublishOperation(deletePayload, AnswerDeletedEventType),
-	); err != nil {
+	}
+	if existing != nil {
+		operations = append(operations, s.createStatsInvalidateOperation(existing.FormID))
+	}
+
+	// Execute cache deletion and publish operations concurrently
+	if err := s.executeAsyncOperations(operations...); err != nil {
 		return fmt.Errorf("failed to complete async operations: %w", err)
 	}
 
 	return nil
 }
 
+// Reject publishes an answer.rejected event telling the requester why its
+// request event was refused
+func (s *Service) Reject(rejection *entity.Rejection) error {
+	if err := s.createPublishOperation(rejection, AnswerRejectedEventType)(); err != nil {
+		return fmt.Errorf("failed to publish rejection: %w", err)
+	}
+
+	return nil
+}
+
 // executeAsyncOperations runs multiple operations concurrently and returns the first error encountered
 func (s *Service) executeAsyncOperations(operations ...func() error) error {
 	var wg sync.WaitGroup
@@ -151,7 +361,7 @@ func (s *Service) createCacheDeleteOperation(id string) func() error {
 
 		return retrier.Do(DefaultRetrierAttempts, DefaultRetryDelay, func() error {
 			key := fmt.Sprintf(AnswerKeyTemplate, id)
-			return s.casher.DeleteFromCash(ctx, key)
+			return s.casher.MarkDeleted(ctx, []string{key})
 		})
 	}
 }
@@ -0,0 +1,466 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/repository"
+	"github.com/Koyo-os/answer-service/internal/service"
+	"github.com/Koyo-os/answer-service/pkg/transport/casher"
+	"github.com/Koyo-os/answer-service/pkg/transport/publisher"
+	"github.com/google/uuid"
+)
+
+type fixture struct {
+	service   *service.Service
+	repo      *repository.MemoryRepository
+	cache     *casher.Memory
+	publisher *publisher.Memory
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	f := &fixture{
+		repo:      repository.NewMemoryRepository(),
+		cache:     casher.NewMemory(),
+		publisher: publisher.NewMemory(),
+	}
+	f.service = service.NewService(f.cache, f.publisher, f.repo, f.repo, 5*time.Second)
+
+	return f
+}
+
+// saveForm stores a one-question text form with the given policy and cap
+func (f *fixture) saveForm(t *testing.T, policy string, maxResponses int) *entity.Form {
+	t.Helper()
+
+	form := &entity.Form{
+		ID:             uuid.New(),
+		ResponsePolicy: policy,
+		MaxResponses:   maxResponses,
+		UpdatedAt:      time.Now().UTC(),
+		Questions: []entity.Question{
+			{OrderNumber: 1, Type: entity.QuestionTypeText, Required: true},
+		},
+	}
+	if err := f.service.SaveForm(form); err != nil {
+		t.Fatalf("SaveForm: %v", err)
+	}
+
+	return form
+}
+
+func newAnswer(formID, userID uuid.UUID, content string) *entity.Answer {
+	answer := &entity.Answer{
+		ID:         uuid.New(),
+		FormID:     formID,
+		UserID:     userID,
+		IsComplete: true,
+	}
+	answer.AddElement(1, content)
+
+	return answer
+}
+
+func rejectReason(err error) string {
+	var rerr *entity.RejectError
+	if errors.As(err, &rerr) {
+		return rerr.Reason
+	}
+	return ""
+}
+
+func TestAddStoresCachesAndPublishes(t *testing.T) {
+	f := newFixture(t)
+	form := f.saveForm(t, "", 0)
+
+	answer := newAnswer(form.ID, uuid.New(), "hello")
+	if err := f.service.Add(answer); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if _, err := f.repo.GetAnswer(t.Context(), answer.ID); err != nil {
+		t.Fatalf("stored answer: %v", err)
+	}
+	if !slices.Contains(f.cache.Keys(), fmt.Sprintf(service.AnswerKeyTemplate, answer.ID)) {
+		t.Fatalf("cache keys = %v, want the answer cached", f.cache.Keys())
+	}
+
+	if got := len(f.publisher.EventsOfType(service.AnswerCreatedEventType)); got != 1 {
+		t.Fatalf("published %d answer.created events, want 1", got)
+	}
+	if got := len(f.publisher.EventsOfType(service.AnswerCompletedEventType)); got != 1 {
+		t.Fatalf("published %d answer.completed events, want 1", got)
+	}
+
+	invalid := newAnswer(form.ID, uuid.New(), "")
+	if reason := rejectReason(f.service.Add(invalid)); reason != entity.RejectReasonIncomplete {
+		t.Fatalf("Add(blank required answer) reason = %q, want %s", reason, entity.RejectReasonIncomplete)
+	}
+}
+
+func TestGetReturnsGrading(t *testing.T) {
+	f := newFixture(t)
+
+	form := &entity.Form{
+		ID:        uuid.New(),
+		UpdatedAt: time.Now().UTC(),
+		Questions: []entity.Question{
+			{OrderNumber: 1, Type: entity.QuestionTypeText, Correct: []string{"paris"}, Points: 2},
+			{OrderNumber: 2, Type: entity.QuestionTypeText, Correct: []string{"rome"}},
+		},
+	}
+	if err := f.service.SaveForm(form); err != nil {
+		t.Fatalf("SaveForm: %v", err)
+	}
+
+	answer := newAnswer(form.ID, uuid.New(), "Paris")
+	answer.AddElement(2, "Oslo")
+	if err := f.service.Add(answer); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	// The first service reads through the cache, the second from the repository
+	cold := service.NewService(casher.NewMemory(), f.publisher, f.repo, f.repo, 5*time.Second)
+
+	for name, s := range map[string]*service.Service{"cached": f.service, "stored": cold} {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.Get(answer.ID.String())
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+
+			if got.Score == nil || *got.Score != 2 || got.MaxScore == nil || *got.MaxScore != 3 {
+				t.Fatalf("Score = %v / %v, want 2 / 3", got.Score, got.MaxScore)
+			}
+
+			for order, want := range map[uint]float64{1: 2, 2: 0} {
+				element := got.GetElementByQuestionOrder(order)
+				if element.Correct == nil || *element.Correct != (want > 0) || element.Points == nil || *element.Points != want {
+					t.Errorf("question %d graded %v / %v, want %v points", order, element.Correct, element.Points, want)
+				}
+			}
+		})
+	}
+
+	if _, err := f.service.Get(uuid.NewString()); !errors.Is(err, entity.ErrAnswerNotFound) {
+		t.Fatalf("Get(unknown) = %v, want %v", err, entity.ErrAnswerNotFound)
+	}
+}
+
+func TestAddSingleResponse(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		f := newFixture(t)
+		form := f.saveForm(t, entity.ResponsePolicySingle, 0)
+		user := uuid.New()
+
+		first := newAnswer(form.ID, user, "first")
+		if err := f.service.Add(first); err != nil {
+			t.Fatalf("Add(first): %v", err)
+		}
+
+		// A redelivered request is not a second submission
+		redelivered := *first
+		if err := f.service.Add(&redelivered); err != nil {
+			t.Fatalf("Add(redelivered): %v", err)
+		}
+
+		err := f.service.Add(newAnswer(form.ID, user, "second"))
+		if !errors.Is(err, entity.ErrAlreadySubmitted) || rejectReason(err) != entity.RejectReasonAlreadySubmitted {
+			t.Fatalf("Add(second) = %v, want already_submitted", err)
+		}
+
+		// Another user may still answer
+		if err := f.service.Add(newAnswer(form.ID, uuid.New(), "other")); err != nil {
+			t.Fatalf("Add(other user): %v", err)
+		}
+	})
+
+	t.Run("single editable", func(t *testing.T) {
+		f := newFixture(t)
+		form := f.saveForm(t, entity.ResponsePolicySingleEditable, 0)
+		user := uuid.New()
+
+		first := newAnswer(form.ID, user, "first")
+		if err := f.service.Add(first); err != nil {
+			t.Fatalf("Add(first): %v", err)
+		}
+
+		second := newAnswer(form.ID, user, "second")
+		if err := f.service.Add(second); err != nil {
+			t.Fatalf("Add(second): %v", err)
+		}
+		if second.ID != first.ID {
+			t.Fatalf("edited answer ID = %s, want the first answer's %s", second.ID, first.ID)
+		}
+
+		stored, err := f.repo.GetAnswer(t.Context(), first.ID)
+		if err != nil {
+			t.Fatalf("GetAnswer: %v", err)
+		}
+		if content := stored.GetElementByQuestionOrder(1).Content; content != "second" {
+			t.Fatalf("stored content = %q, want second", content)
+		}
+		if got := len(f.publisher.EventsOfType(service.AnswerUpdatedEventType)); got != 1 {
+			t.Fatalf("published %d answer.updated events, want 1", got)
+		}
+	})
+}
+
+func TestAddRedelivered(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+	}{
+		{"multiple", 0},
+		{"multiple capped", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			form := f.saveForm(t, entity.ResponsePolicyMultiple, tt.max)
+
+			answer := newAnswer(form.ID, uuid.New(), "once")
+			if err := f.service.Add(answer); err != nil {
+				t.Fatalf("Add: %v", err)
+			}
+
+			// The broker redelivers the request when its ack was lost
+			redelivered := *answer
+			if err := f.service.Add(&redelivered); err != nil {
+				t.Fatalf("Add(redelivered) = %v, want nil", err)
+			}
+
+			if got := len(f.publisher.EventsOfType(service.AnswerCreatedEventType)); got != 1 {
+				t.Fatalf("published %d answer.created events, want 1", got)
+			}
+		})
+	}
+}
+
+func TestAddCappedForm(t *testing.T) {
+	f := newFixture(t)
+	form := f.saveForm(t, "", 2)
+
+	for i := range 2 {
+		if err := f.service.Add(newAnswer(form.ID, uuid.New(), "ok")); err != nil {
+			t.Fatalf("Add(%d): %v", i, err)
+		}
+	}
+
+	err := f.service.Add(newAnswer(form.ID, uuid.New(), "late"))
+	if !errors.Is(err, entity.ErrFormFull) || rejectReason(err) != entity.RejectReasonFormFull {
+		t.Fatalf("Add(over cap) = %v, want form_full", err)
+	}
+
+	if got := len(f.publisher.EventsOfType(service.AnswerCreatedEventType)); got != 2 {
+		t.Fatalf("published %d answer.created events, want 2", got)
+	}
+}
+
+func TestAddToUnknownForm(t *testing.T) {
+	f := newFixture(t)
+
+	// Without a form definition the answer is stored unchecked
+	answer := newAnswer(uuid.New(), uuid.New(), "anything")
+	if err := f.service.Add(answer); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	f.service.SetRequireKnownForms(true)
+
+	err := f.service.Add(newAnswer(uuid.New(), uuid.New(), "anything"))
+	if reason := rejectReason(err); reason != entity.RejectReasonFormNotFound {
+		t.Fatalf("Add(require known) reason = %q, want %s", reason, entity.RejectReasonFormNotFound)
+	}
+}
+
+func TestDeleteFormAnswers(t *testing.T) {
+	f := newFixture(t)
+	form := f.saveForm(t, "", 0)
+	other := f.saveForm(t, "", 0)
+
+	var ids []uuid.UUID
+	for range 3 {
+		answer := newAnswer(form.ID, uuid.New(), "x")
+		if err := f.service.Add(answer); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		ids = append(ids, answer.ID)
+	}
+
+	kept := newAnswer(other.ID, uuid.New(), "y")
+	if err := f.service.Add(kept); err != nil {
+		t.Fatalf("Add(other form): %v", err)
+	}
+
+	deleted, err := f.service.DeleteFormAnswers(form.ID)
+	if err != nil || deleted != 3 {
+		t.Fatalf("DeleteFormAnswers = %d, %v, want 3", deleted, err)
+	}
+
+	for _, id := range ids {
+		if _, err := f.repo.GetAnswer(t.Context(), id); !errors.Is(err, entity.ErrAnswerNotFound) {
+			t.Fatalf("GetAnswer(%s) after delete = %v, want ErrAnswerNotFound", id, err)
+		}
+		if _, err := f.cache.GetCashFor(t.Context(), fmt.Sprintf(service.AnswerKeyTemplate, id)); !errors.Is(err, casher.ErrCacheMiss) {
+			t.Fatalf("answer %s is still cached: %v", id, err)
+		}
+	}
+	if _, err := f.repo.GetAnswer(t.Context(), kept.ID); err != nil {
+		t.Fatalf("answer to another form was deleted: %v", err)
+	}
+
+	events := f.publisher.EventsOfType(service.AnswerBulkDeletedEventType)
+	if len(events) != 1 {
+		t.Fatalf("published %d answer.bulk_deleted events, want 1", len(events))
+	}
+
+	payload := new(service.BulkDeletePayload)
+	if err := json.Unmarshal(events[0].Payload, payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.FormID != form.ID.String() || payload.Count != 3 {
+		t.Fatalf("payload = %+v, want form %s and count 3", payload, form.ID)
+	}
+
+	// Nothing left: nothing deleted, nothing published
+	if deleted, err := f.service.DeleteFormAnswers(form.ID); err != nil || deleted != 0 {
+		t.Fatalf("DeleteFormAnswers(again) = %d, %v, want 0", deleted, err)
+	}
+	if got := len(f.publisher.EventsOfType(service.AnswerBulkDeletedEventType)); got != 1 {
+		t.Fatalf("published %d answer.bulk_deleted events, want still 1", got)
+	}
+}
+
+func TestDeletedAnswerIsNotRewarmed(t *testing.T) {
+	f := newFixture(t)
+	form := f.saveForm(t, "", 0)
+
+	answer := newAnswer(form.ID, uuid.New(), "x")
+	if err := f.service.Add(answer); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	// The warmer read the answer before it was deleted
+	stale := *answer
+	if err := f.service.Delete(answer.ID.String()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	key := fmt.Sprintf(service.AnswerKeyTemplate, answer.ID)
+	if err := f.cache.DoCashingBatch(t.Context(), map[string]any{key: &stale}); err != nil {
+		t.Fatalf("DoCashingBatch: %v", err)
+	}
+	if _, err := f.cache.GetCashFor(t.Context(), key); !errors.Is(err, casher.ErrCacheMiss) {
+		t.Fatalf("deleted answer was cached again: %v", err)
+	}
+}
+
+// racingRepository runs after once, right after the first StreamAnswers
+// has read the answers
+type racingRepository struct {
+	*repository.MemoryRepository
+	after func()
+}
+
+func (r *racingRepository) StreamAnswers(ctx context.Context, filter repository.AnswerFilter, size int, fn func([]entity.Answer) error) error {
+	err := r.MemoryRepository.StreamAnswers(ctx, filter, size, fn)
+
+	if after := r.after; after != nil {
+		r.after = nil
+		after()
+	}
+
+	return err
+}
+
+func TestFormStatsIgnoreResultsOutdatedWhileComputed(t *testing.T) {
+	f := newFixture(t)
+	repo := &racingRepository{MemoryRepository: f.repo}
+	f.service = service.NewService(f.cache, f.publisher, repo, f.repo, 5*time.Second)
+
+	form := f.saveForm(t, "", 0)
+	if err := f.service.Add(newAnswer(form.ID, uuid.New(), "x")); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	// An answer lands after the statistics were read but before they're cached
+	repo.after = func() {
+		if err := f.service.Add(newAnswer(form.ID, uuid.New(), "y")); err != nil {
+			t.Errorf("Add while computing: %v", err)
+		}
+	}
+
+	stale, err := f.service.FormStats(t.Context(), form.ID)
+	if err != nil || stale.Responses != 1 {
+		t.Fatalf("FormStats = %+v, %v, want 1 response", stale, err)
+	}
+
+	fresh, err := f.service.FormStats(t.Context(), form.ID)
+	if err != nil || fresh.Responses != 2 {
+		t.Fatalf("FormStats after the race = %+v, %v, want 2 responses", fresh, err)
+	}
+
+	if _, err := f.service.FormStats(t.Context(), uuid.New()); !errors.Is(err, entity.ErrFormNotFound) {
+		t.Fatalf("FormStats(unknown form) = %v, want ErrFormNotFound", err)
+	}
+}
+
+// staleProvider still serves forms the form service has deleted
+type staleProvider struct {
+	form *entity.Form
+}
+
+func (p *staleProvider) GetForm(_ context.Context, id uuid.UUID) (*entity.Form, error) {
+	if p.form == nil || p.form.ID != id {
+		return nil, entity.ErrFormNotFound
+	}
+	return p.form, nil
+}
+
+func TestAddToDeletedForm(t *testing.T) {
+	tests := []struct {
+		name         string
+		requireKnown bool
+		provider     bool
+	}{
+		{"default", false, false},
+		{"known forms required", true, false},
+		{"provider lags behind the deletion", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			form := f.saveForm(t, "", 0)
+
+			f.service.SetRequireKnownForms(tt.requireKnown)
+			if tt.provider {
+				f.service.SetFormProvider(&staleProvider{form: form})
+			}
+
+			if err := f.service.DeleteForm(form.ID.String()); err != nil {
+				t.Fatalf("DeleteForm: %v", err)
+			}
+
+			err := f.service.Add(newAnswer(form.ID, uuid.New(), "late"))
+			if reason := rejectReason(err); reason != entity.RejectReasonFormDeleted || !errors.Is(err, entity.ErrFormDeleted) {
+				t.Fatalf("Add(deleted form) = %v, want %s", err, entity.RejectReasonFormDeleted)
+			}
+
+			count, err := f.repo.CountAnswers(t.Context(), repository.AnswerFilter{FormIDs: []uuid.UUID{form.ID}})
+			if err != nil || count != 0 {
+				t.Fatalf("CountAnswers = %d, %v, want no orphan answers", count, err)
+			}
+		})
+	}
+}
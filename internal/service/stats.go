@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/repository"
+	"github.com/Koyo-os/answer-service/internal/stats"
+	"github.com/Koyo-os/answer-service/pkg/retrier"
+	"github.com/google/uuid"
+)
+
+const (
+	// StatsKeyTemplate is filled with the form ID and its statistics version
+	StatsKeyTemplate = "stats:%s:%d"
+
+	// StatsVersionKeyTemplate holds the statistics version of a form, bumped
+	// whenever the form or one of its answers changes
+	StatsVersionKeyTemplate = "stats-version:%s"
+
+	// DefaultStatsBatchSize is how many answers are read at a time when
+	// computing statistics
+	DefaultStatsBatchSize = 500
+)
+
+// FormStats returns the statistics of the answers to a form, reading through the cache
+// The statistics are cached under the form's current version, which is
+// bumped whenever an answer to the form or the form itself changes, so a
+// result computed while a change lands is stored under a version no reader
+// asks for anymore. Returns entity.ErrFormNotFound if the form is unknown.
+func (s *Service) FormStats(ctx context.Context, formID uuid.UUID) (*stats.FormStats, error) {
+	load := func(ctx context.Context) (any, error) {
+		form, err := s.formFor(ctx, formID)
+		if errors.Is(err, entity.ErrFormNotFound) || errors.Is(err, entity.ErrFormDeleted) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		aggregator := stats.NewAggregator(form)
+
+		filter := repository.AnswerFilter{FormIDs: []uuid.UUID{formID}}
+		if err := s.repository.StreamAnswers(ctx, filter, DefaultStatsBatchSize, aggregator.Add); err != nil {
+			return nil, err
+		}
+
+		return aggregator.Result(), nil
+	}
+
+	version, err := s.casher.Version(ctx, fmt.Sprintf(StatsVersionKeyTemplate, formID.String()))
+	if err != nil {
+		// Without the version nothing cached can be trusted to be current
+		loaded, err := load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get form statistics: %w", err)
+		}
+		if loaded == nil {
+			return nil, entity.ErrFormNotFound
+		}
+		return loaded.(*stats.FormStats), nil
+	}
+
+	result := new(stats.FormStats)
+
+	found, err := s.casher.Fetch(ctx, fmt.Sprintf(StatsKeyTemplate, formID.String(), version), result, load)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form statistics: %w", err)
+	}
+	if !found {
+		return nil, entity.ErrFormNotFound
+	}
+
+	return result, nil
+}
+
+// createStatsInvalidateOperation bumps the statistics version of a form and
+// drops the statistics cached under the previous one
+func (s *Service) createStatsInvalidateOperation(formID uuid.UUID) func() error {
+	return func() error {
+		ctx, cancel := s.getContext()
+		defer cancel()
+
+		return retrier.Do(DefaultRetrierAttempts, DefaultRetryDelay, func() error {
+			version, err := s.casher.BumpVersion(ctx, fmt.Sprintf(StatsVersionKeyTemplate, formID.String()))
+			if err != nil {
+				return err
+			}
+
+			return s.casher.DeleteFromCash(ctx, fmt.Sprintf(StatsKeyTemplate, formID.String(), version-1))
+		})
+	}
+}
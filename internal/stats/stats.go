@@ -0,0 +1,165 @@
+// Package stats aggregates the answers to a form into per-question summaries
+package stats
+
+import (
+	"slices"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/validation"
+	"github.com/google/uuid"
+)
+
+type (
+	// FormStats summarises every answer to a form
+	// CompletionRate is Completed / Responses, 0 when there are no responses.
+	FormStats struct {
+		FormID         uuid.UUID       `json:"form_id"`
+		Responses      int             `json:"responses"`
+		Completed      int             `json:"completed"`
+		CompletionRate float64         `json:"completion_rate"`
+		Questions      []QuestionStats `json:"questions"`
+		ComputedAt     time.Time       `json:"computed_at"`
+	}
+
+	// QuestionStats summarises the answers to one question
+	// Options counts how often each option was chosen (choice questions);
+	// Numeric describes the values of number and rating questions.
+	QuestionStats struct {
+		OrderNumber uint           `json:"order_number"`
+		Type        string         `json:"type"`
+		Responses   int            `json:"responses"`
+		Options     map[string]int `json:"options,omitempty"`
+		Numeric     *NumericStats  `json:"numeric,omitempty"`
+	}
+
+	NumericStats struct {
+		Count  int     `json:"count"`
+		Mean   float64 `json:"mean"`
+		Median float64 `json:"median"`
+		Min    float64 `json:"min"`
+		Max    float64 `json:"max"`
+	}
+
+	// Aggregator builds the FormStats of a form from batches of its answers
+	Aggregator struct {
+		form      *entity.Form
+		stats     *FormStats
+		questions map[uint]*QuestionStats
+		numbers   map[uint][]float64
+	}
+)
+
+func NewAggregator(form *entity.Form) *Aggregator {
+	a := &Aggregator{
+		form: form,
+		stats: &FormStats{
+			FormID:    form.ID,
+			Questions: make([]QuestionStats, len(form.Questions)),
+		},
+		questions: make(map[uint]*QuestionStats, len(form.Questions)),
+		numbers:   make(map[uint][]float64),
+	}
+
+	for i, question := range form.Questions {
+		stats := &a.stats.Questions[i]
+		stats.OrderNumber = question.OrderNumber
+		stats.Type = question.Type
+
+		if isChoice(question.Type) {
+			stats.Options = make(map[string]int, len(question.Options))
+			for _, option := range question.Options {
+				stats.Options[option] = 0
+			}
+		}
+
+		a.questions[question.OrderNumber] = stats
+	}
+
+	return a
+}
+
+// Add counts a batch of answers to the form
+// It matches the callback of Repository.StreamAnswers and never fails.
+func (a *Aggregator) Add(answers []entity.Answer) error {
+	for i := range answers {
+		a.stats.Responses++
+		if answers[i].IsComplete {
+			a.stats.Completed++
+		}
+
+		for _, element := range answers[i].Elements {
+			a.addElement(&element)
+		}
+	}
+
+	return nil
+}
+
+func (a *Aggregator) addElement(element *entity.Element) {
+	question := a.form.GetQuestion(element.QuestionOrderNumber)
+	if question == nil || validation.IsBlank(question, element.Content) {
+		return
+	}
+
+	stats := a.questions[question.OrderNumber]
+	stats.Responses++
+
+	switch question.Type {
+	case entity.QuestionTypeSingleChoice:
+		stats.Options[element.Content]++
+	case entity.QuestionTypeMultipleChoice:
+		if choices, ok := element.List(); ok {
+			for _, choice := range choices {
+				stats.Options[choice]++
+			}
+		}
+	case entity.QuestionTypeNumber, entity.QuestionTypeRating:
+		if value, ok := element.Number(); ok {
+			a.numbers[question.OrderNumber] = append(a.numbers[question.OrderNumber], value)
+		}
+	}
+}
+
+// Result returns the statistics of the answers added so far
+func (a *Aggregator) Result() *FormStats {
+	if a.stats.Responses > 0 {
+		a.stats.CompletionRate = float64(a.stats.Completed) / float64(a.stats.Responses)
+	}
+
+	for order, values := range a.numbers {
+		a.questions[order].Numeric = describe(values)
+	}
+
+	a.stats.ComputedAt = time.Now().UTC()
+
+	return a.stats
+}
+
+func describe(values []float64) *NumericStats {
+	slices.Sort(values)
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+
+	n := len(values)
+
+	median := values[n/2]
+	if n%2 == 0 {
+		median = (values[n/2-1] + values[n/2]) / 2
+	}
+
+	return &NumericStats{
+		Count:  n,
+		Mean:   sum / float64(n),
+		Median: median,
+		Min:    values[0],
+		Max:    values[n-1],
+	}
+}
+
+func isChoice(questionType string) bool {
+	return questionType == entity.QuestionTypeSingleChoice || questionType == entity.QuestionTypeMultipleChoice
+}
@@ -0,0 +1,168 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   NumericStats
+	}{
+		{"single value", []float64{7}, NumericStats{Count: 1, Mean: 7, Median: 7, Min: 7, Max: 7}},
+		{"odd count", []float64{5, 1, 3}, NumericStats{Count: 3, Mean: 3, Median: 3, Min: 1, Max: 5}},
+		{"even count", []float64{4, 1, 10, 2}, NumericStats{Count: 4, Mean: 4.25, Median: 3, Min: 1, Max: 10}},
+		{"negative values", []float64{-2, -6}, NumericStats{Count: 2, Mean: -4, Median: -4, Min: -6, Max: -2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describe(tt.values); *got != tt.want {
+				t.Fatalf("describe(%v) = %+v, want %+v", tt.values, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	form := &entity.Form{
+		ID: uuid.New(),
+		Questions: []entity.Question{
+			{OrderNumber: 1, Type: entity.QuestionTypeSingleChoice, Options: []string{"a", "b", "c"}},
+			{OrderNumber: 2, Type: entity.QuestionTypeMultipleChoice, Options: []string{"x", "y"}},
+			{OrderNumber: 3, Type: entity.QuestionTypeRating},
+			{OrderNumber: 4, Type: entity.QuestionTypeText},
+		},
+	}
+
+	answer := func(complete bool, contents ...string) entity.Answer {
+		a := entity.Answer{ID: uuid.New(), FormID: form.ID, IsComplete: complete}
+		for i, content := range contents {
+			if content != "" {
+				a.AddElement(uint(i+1), content)
+			}
+		}
+		return a
+	}
+
+	aggregator := NewAggregator(form)
+
+	// Answers arrive in batches, as from Repository.StreamAnswers
+	batches := [][]entity.Answer{
+		{
+			answer(true, "a", `["x","y"]`, "5", "hello"),
+			answer(true, "a", `["x"]`, "3"),
+		},
+		{
+			answer(false, "b", "", "4", " "),
+			answer(true, "", `[]`, "", ""),
+		},
+	}
+	for _, batch := range batches {
+		if err := aggregator.Add(batch); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	result := aggregator.Result()
+
+	if result.FormID != form.ID || result.Responses != 4 || result.Completed != 3 {
+		t.Fatalf("result = %+v, want 4 responses, 3 completed", result)
+	}
+	if result.CompletionRate != 0.75 {
+		t.Fatalf("CompletionRate = %v, want 0.75", result.CompletionRate)
+	}
+	if len(result.Questions) != 4 {
+		t.Fatalf("got %d question stats, want 4", len(result.Questions))
+	}
+
+	single := result.Questions[0]
+	if single.Responses != 3 || single.Options["a"] != 2 || single.Options["b"] != 1 {
+		t.Errorf("single choice stats = %+v, want a:2 b:1 over 3 responses", single)
+	}
+	if count, ok := single.Options["c"]; !ok || count != 0 {
+		t.Errorf("unchosen option c = %d, %v, want listed with 0", count, ok)
+	}
+
+	multiple := result.Questions[1]
+	if multiple.Responses != 2 || multiple.Options["x"] != 2 || multiple.Options["y"] != 1 {
+		t.Errorf("multiple choice stats = %+v, want x:2 y:1 over 2 responses", multiple)
+	}
+
+	rating := result.Questions[2]
+	want := NumericStats{Count: 3, Mean: 4, Median: 4, Min: 3, Max: 5}
+	if rating.Responses != 3 || rating.Numeric == nil || *rating.Numeric != want {
+		t.Errorf("rating stats = %+v (numeric %+v), want %+v", rating, rating.Numeric, want)
+	}
+
+	text := result.Questions[3]
+	if text.Responses != 1 || text.Options != nil || text.Numeric != nil {
+		t.Errorf("text stats = %+v, want 1 response and no breakdown", text)
+	}
+}
+
+func TestAggregatorTypedValues(t *testing.T) {
+	form := &entity.Form{
+		ID: uuid.New(),
+		Questions: []entity.Question{
+			{OrderNumber: 1, Type: entity.QuestionTypeMultipleChoice, Options: []string{"x", "y"}},
+			{OrderNumber: 2, Type: entity.QuestionTypeNumber},
+		},
+	}
+
+	typed := entity.Answer{ID: uuid.New(), FormID: form.ID, IsComplete: true}
+	for _, element := range []struct {
+		order     uint
+		valueType string
+		value     any
+	}{
+		{1, entity.ValueTypeList, []string{"x", "y"}},
+		{2, entity.ValueTypeNumber, 2.5},
+	} {
+		e := entity.Element{QuestionOrderNumber: element.order}
+		if err := e.SetValue(element.valueType, element.value); err != nil {
+			t.Fatalf("SetValue(%v): %v", element.value, err)
+		}
+		typed.Elements = append(typed.Elements, e)
+	}
+
+	// Elements sent as strings before typed values still count
+	legacy := entity.Answer{ID: uuid.New(), FormID: form.ID, IsComplete: true}
+	legacy.AddElement(1, `["y"]`)
+	legacy.AddElement(2, "4.5")
+
+	aggregator := NewAggregator(form)
+	if err := aggregator.Add([]entity.Answer{typed, legacy}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	result := aggregator.Result()
+
+	multiple := result.Questions[0]
+	if multiple.Responses != 2 || multiple.Options["x"] != 1 || multiple.Options["y"] != 2 {
+		t.Errorf("multiple choice stats = %+v, want x:1 y:2 over 2 responses", multiple)
+	}
+
+	number := result.Questions[1]
+	want := NumericStats{Count: 2, Mean: 3.5, Median: 3.5, Min: 2.5, Max: 4.5}
+	if number.Responses != 2 || number.Numeric == nil || *number.Numeric != want {
+		t.Errorf("number stats = %+v (numeric %+v), want %+v", number, number.Numeric, want)
+	}
+}
+
+func TestAggregatorWithoutAnswers(t *testing.T) {
+	form := &entity.Form{
+		ID:        uuid.New(),
+		Questions: []entity.Question{{OrderNumber: 1, Type: entity.QuestionTypeNumber}},
+	}
+
+	result := NewAggregator(form).Result()
+
+	if result.Responses != 0 || result.CompletionRate != 0 || result.Questions[0].Numeric != nil {
+		t.Fatalf("result = %+v, want empty statistics", result)
+	}
+}
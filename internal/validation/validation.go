@@ -0,0 +1,284 @@
+// Package validation checks answers against the question definitions of their form
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+)
+
+const DateLayout = entity.DateLayout
+
+// Rating scale used when a rating question sets no bounds
+const (
+	DefaultRatingMin = 1
+	DefaultRatingMax = 5
+)
+
+// Validate checks every element of answer against its question in form and,
+// when the answer is marked complete, that every required question is answered
+// Returns an *entity.RejectError listing the problems, or nil
+func Validate(form *entity.Form, answer *entity.Answer) error {
+	if fields := ValidateElements(form, answer); len(fields) > 0 {
+		return &entity.RejectError{
+			Reason: entity.RejectReasonInvalidAnswer,
+			Fields: fields,
+		}
+	}
+
+	if answer.IsComplete {
+		return CheckComplete(form, answer)
+	}
+
+	return nil
+}
+
+// CheckComplete refuses completion of answer while required questions of
+// form are unanswered, listing their order numbers in the error
+func CheckComplete(form *entity.Form, answer *entity.Answer) error {
+	missing := MissingRequired(form, answer)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	fields := make([]entity.FieldError, len(missing))
+	for i, order := range missing {
+		fields[i] = entity.FieldError{
+			Field:   "elements",
+			Message: fmt.Sprintf("required question %d is not answered", order),
+		}
+	}
+
+	return &entity.RejectError{
+		Reason:  entity.RejectReasonIncomplete,
+		Fields:  fields,
+		Missing: missing,
+	}
+}
+
+// MissingRequired returns the order numbers of the required questions of
+// form that answer leaves unanswered, in form order
+// Questions hidden by skip logic are never required
+func MissingRequired(form *entity.Form, answer *entity.Answer) []uint {
+	var missing []uint
+
+	visibility := NewVisibility(form, answer)
+
+	for _, question := range form.Questions {
+		if !question.Required || !visibility.IsVisible(question.OrderNumber) {
+			continue
+		}
+
+		element := answer.GetElementByQuestionOrder(question.OrderNumber)
+		if element == nil || IsBlank(&question, element.Content) {
+			missing = append(missing, question.OrderNumber)
+		}
+	}
+
+	return missing
+}
+
+// IsBlank reports whether content leaves question unanswered
+func IsBlank(question *entity.Question, content string) bool {
+	if strings.TrimSpace(content) == "" {
+		return true
+	}
+
+	if question.Type == entity.QuestionTypeMultipleChoice {
+		var choices []string
+		return json.Unmarshal([]byte(content), &choices) == nil && len(choices) == 0
+	}
+
+	return false
+}
+
+// ValidateElements returns one error per element that doesn't refer to a
+// question of form, answers a question twice, answers a question hidden by
+// skip logic or doesn't fit its question
+func ValidateElements(form *entity.Form, answer *entity.Answer) []entity.FieldError {
+	var fields []entity.FieldError
+
+	seen := make(map[uint]bool, len(answer.Elements))
+	visibility := NewVisibility(form, answer)
+
+	for i, element := range answer.Elements {
+		order := element.QuestionOrderNumber
+
+		question := form.GetQuestion(order)
+		if question == nil {
+			fields = append(fields, entity.FieldError{
+				Field:   fmt.Sprintf("elements.%d.question_order_number", i),
+				Message: fmt.Sprintf("question %d is not in the form", order),
+			})
+			continue
+		}
+
+		if seen[order] {
+			fields = append(fields, entity.FieldError{
+				Field:   fmt.Sprintf("elements.%d.question_order_number", i),
+				Message: fmt.Sprintf("question %d is answered more than once", order),
+			})
+			continue
+		}
+		seen[order] = true
+
+		if !visibility.IsVisible(order) {
+			fields = append(fields, entity.FieldError{
+				Field:   fmt.Sprintf("elements.%d.question_order_number", i),
+				Message: fmt.Sprintf("question %d is hidden by the answers given", order),
+			})
+			continue
+		}
+
+		if err := checkValueType(question, element.ValueType); err != nil {
+			fields = append(fields, entity.FieldError{
+				Field:   fmt.Sprintf("elements.%d.value_type", i),
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		if err := ValidateContent(question, element.Content); err != nil {
+			fields = append(fields, entity.FieldError{
+				Field:   fmt.Sprintf("elements.%d.content", i),
+				Message: err.Error(),
+			})
+		}
+	}
+
+	return fields
+}
+
+// valueTypes maps each question type to the typed value it takes
+// Strings are accepted for every question type, as they were before typed values
+var valueTypes = map[string]string{
+	entity.QuestionTypeNumber:         entity.ValueTypeNumber,
+	entity.QuestionTypeRating:         entity.ValueTypeNumber,
+	entity.QuestionTypeDate:           entity.ValueTypeDate,
+	entity.QuestionTypeMultipleChoice: entity.ValueTypeList,
+}
+
+func checkValueType(question *entity.Question, valueType string) error {
+	if valueType == "" || valueType == entity.ValueTypeString {
+		return nil
+	}
+
+	if valueTypes[question.Type] != valueType {
+		return fmt.Errorf("%s value doesn't fit a %s question", valueType, question.Type)
+	}
+
+	return nil
+}
+
+// ValidateContent checks content against the type, bounds, options and
+// pattern of question
+func ValidateContent(question *entity.Question, content string) error {
+	if err := validateType(question, content); err != nil {
+		return err
+	}
+
+	if question.Pattern != "" {
+		re, err := regexp.Compile(question.Pattern)
+		if err != nil {
+			return fmt.Errorf("question %d has an invalid pattern", question.OrderNumber)
+		}
+		if !re.MatchString(content) {
+			return fmt.Errorf("does not match pattern %s", question.Pattern)
+		}
+	}
+
+	return nil
+}
+
+func validateType(question *entity.Question, content string) error {
+	switch question.Type {
+	case entity.QuestionTypeText:
+		return checkRange(question, float64(len([]rune(content))), "length")
+	case entity.QuestionTypeNumber:
+		value, err := strconv.ParseFloat(content, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("%q is not a number", content)
+		}
+		return checkRange(question, value, "value")
+	case entity.QuestionTypeRating:
+		value, err := strconv.Atoi(content)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", content)
+		}
+		return checkRating(question, value)
+	case entity.QuestionTypeDate:
+		if _, err := time.Parse(DateLayout, content); err != nil {
+			return fmt.Errorf("%q is not a date (YYYY-MM-DD)", content)
+		}
+		return nil
+	case entity.QuestionTypeEmail:
+		addr, err := mail.ParseAddress(content)
+		if err != nil || addr.Address != content {
+			return fmt.Errorf("%q is not an email address", content)
+		}
+		return nil
+	case entity.QuestionTypeSingleChoice:
+		if !slices.Contains(question.Options, content) {
+			return fmt.Errorf("%q is not one of the options", content)
+		}
+		return nil
+	case entity.QuestionTypeMultipleChoice:
+		return checkChoices(question, content)
+	default:
+		return fmt.Errorf("question %d has unknown type %q", question.OrderNumber, question.Type)
+	}
+}
+
+func checkRange(question *entity.Question, value float64, what string) error {
+	if question.Min != nil && value < *question.Min {
+		return fmt.Errorf("%s %v is below the minimum %v", what, value, *question.Min)
+	}
+	if question.Max != nil && value > *question.Max {
+		return fmt.Errorf("%s %v is above the maximum %v", what, value, *question.Max)
+	}
+	return nil
+}
+
+func checkRating(question *entity.Question, value int) error {
+	low, high := float64(DefaultRatingMin), float64(DefaultRatingMax)
+	if question.Min != nil {
+		low = *question.Min
+	}
+	if question.Max != nil {
+		high = *question.Max
+	}
+
+	if float64(value) < low || float64(value) > high {
+		return fmt.Errorf("rating %d is outside the scale %v-%v", value, low, high)
+	}
+	return nil
+}
+
+// checkChoices expects a JSON array of distinct options
+func checkChoices(question *entity.Question, content string) error {
+	var choices []string
+	if err := json.Unmarshal([]byte(content), &choices); err != nil {
+		return fmt.Errorf("must be a JSON array of options")
+	}
+
+	seen := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		if !slices.Contains(question.Options, choice) {
+			return fmt.Errorf("%q is not one of the options", choice)
+		}
+		if seen[choice] {
+			return fmt.Errorf("%q is chosen more than once", choice)
+		}
+		seen[choice] = true
+	}
+
+	return checkRange(question, float64(len(choices)), "number of choices")
+}
@@ -0,0 +1,227 @@
+package validation_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/validation"
+	"github.com/google/uuid"
+)
+
+// newAnswer answers question i+1 with contents[i], skipping empty strings
+func newAnswer(complete bool, contents ...string) *entity.Answer {
+	answer := &entity.Answer{ID: uuid.New(), UserID: uuid.New(), IsComplete: complete}
+
+	for i, content := range contents {
+		if content != "" {
+			answer.AddElement(uint(i+1), content)
+		}
+	}
+
+	return answer
+}
+
+func bound(v float64) *float64 {
+	return &v
+}
+
+func TestValidate(t *testing.T) {
+	form := &entity.Form{
+		ID: uuid.New(),
+		Questions: []entity.Question{
+			{OrderNumber: 1, Type: entity.QuestionTypeSingleChoice, Options: []string{"yes", "no"}, Required: true},
+			{OrderNumber: 2, Type: entity.QuestionTypeNumber, Min: bound(0), Max: bound(10)},
+			{
+				// Only asked when question 1 is answered yes
+				OrderNumber: 3,
+				Type:        entity.QuestionTypeText,
+				Required:    true,
+				VisibleIf: &entity.VisibilityRule{Conditions: []entity.Condition{
+					{Question: 1, Operator: entity.ConditionEquals, Value: "yes"},
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		answer  *entity.Answer
+		reason  string
+		missing []uint
+		field   string
+	}{
+		{name: "valid complete", answer: newAnswer(true, "yes", "5", "because")},
+		{name: "valid partial", answer: newAnswer(false, "", "5")},
+		{name: "hidden required question not needed", answer: newAnswer(true, "no", "5")},
+		{
+			name:   "content doesn't fit",
+			answer: newAnswer(false, "maybe"),
+			reason: entity.RejectReasonInvalidAnswer,
+			field:  "elements.0.content",
+		},
+		{
+			name:   "out of range",
+			answer: newAnswer(false, "", "11"),
+			reason: entity.RejectReasonInvalidAnswer,
+			field:  "elements.0.content",
+		},
+		{
+			name:   "answer to a hidden question",
+			answer: newAnswer(false, "no", "", "why not"),
+			reason: entity.RejectReasonInvalidAnswer,
+			field:  "elements.1.question_order_number",
+		},
+		{
+			name: "question not in the form",
+			answer: func() *entity.Answer {
+				answer := newAnswer(false, "yes")
+				answer.AddElement(9, "x")
+				return answer
+			}(),
+			reason: entity.RejectReasonInvalidAnswer,
+			field:  "elements.1.question_order_number",
+		},
+		{
+			name:    "required questions missing",
+			answer:  newAnswer(true, "", "5"),
+			reason:  entity.RejectReasonIncomplete,
+			missing: []uint{1},
+		},
+		{
+			name:    "shown required question missing",
+			answer:  newAnswer(true, "yes", "5", " "),
+			reason:  entity.RejectReasonIncomplete,
+			missing: []uint{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation.Validate(form, tt.answer)
+
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+
+			var rerr *entity.RejectError
+			if !errors.As(err, &rerr) {
+				t.Fatalf("Validate = %v, want *entity.RejectError", err)
+			}
+			if rerr.Reason != tt.reason {
+				t.Fatalf("reason = %s, want %s", rerr.Reason, tt.reason)
+			}
+			if !slices.Equal(rerr.Missing, tt.missing) {
+				t.Fatalf("missing = %v, want %v", rerr.Missing, tt.missing)
+			}
+			if tt.field != "" && (len(rerr.Fields) != 1 || rerr.Fields[0].Field != tt.field) {
+				t.Fatalf("fields = %+v, want one error on %s", rerr.Fields, tt.field)
+			}
+		})
+	}
+}
+
+func TestMissingRequired(t *testing.T) {
+	form := &entity.Form{
+		Questions: []entity.Question{
+			{OrderNumber: 1, Type: entity.QuestionTypeText, Required: true},
+			{OrderNumber: 2, Type: entity.QuestionTypeMultipleChoice, Options: []string{"a", "b"}, Required: true},
+			{OrderNumber: 3, Type: entity.QuestionTypeText},
+			{OrderNumber: 4, Type: entity.QuestionTypeNumber, Required: true},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		answer *entity.Answer
+		want   []uint
+	}{
+		{"all answered", newAnswer(true, "x", `["a"]`, "", "1"), nil},
+		{"nothing answered", newAnswer(true), []uint{1, 2, 4}},
+		{"blank answers count as missing", newAnswer(true, "  ", `[]`, "z", "0"), []uint{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validation.MissingRequired(form, tt.answer); !slices.Equal(got, tt.want) {
+				t.Fatalf("MissingRequired = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBlank(t *testing.T) {
+	text := &entity.Question{Type: entity.QuestionTypeText}
+	choices := &entity.Question{Type: entity.QuestionTypeMultipleChoice}
+
+	tests := []struct {
+		name     string
+		question *entity.Question
+		content  string
+		want     bool
+	}{
+		{"empty", text, "", true},
+		{"whitespace", text, " \t\n", true},
+		{"text", text, "x", false},
+		{"zero is an answer", &entity.Question{Type: entity.QuestionTypeNumber}, "0", false},
+		{"no choices", choices, "[]", true},
+		{"no choices with space", choices, " [ ] ", true},
+		{"some choices", choices, `["a"]`, false},
+		{"invalid choices aren't blank", choices, "a", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validation.IsBlank(tt.question, tt.content); got != tt.want {
+				t.Fatalf("IsBlank(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		question entity.Question
+		content  string
+		wantErr  string
+	}{
+		{"text within length", entity.Question{Type: entity.QuestionTypeText, Max: bound(3)}, "abc", ""},
+		{"text too long", entity.Question{Type: entity.QuestionTypeText, Max: bound(3)}, "abcd", "above the maximum"},
+		{"number", entity.Question{Type: entity.QuestionTypeNumber}, "-1.5", ""},
+		{"not a number", entity.Question{Type: entity.QuestionTypeNumber}, "NaN", "not a number"},
+		{"rating in default scale", entity.Question{Type: entity.QuestionTypeRating}, "5", ""},
+		{"rating outside default scale", entity.Question{Type: entity.QuestionTypeRating}, "6", "outside the scale"},
+		{"rating not whole", entity.Question{Type: entity.QuestionTypeRating}, "2.5", "not a whole number"},
+		{"date", entity.Question{Type: entity.QuestionTypeDate}, "2024-02-29", ""},
+		{"not a date", entity.Question{Type: entity.QuestionTypeDate}, "29.02.2024", "not a date"},
+		{"email", entity.Question{Type: entity.QuestionTypeEmail}, "a@b.com", ""},
+		{"email with name", entity.Question{Type: entity.QuestionTypeEmail}, "A <a@b.com>", "not an email"},
+		{"unknown option", entity.Question{Type: entity.QuestionTypeSingleChoice, Options: []string{"a"}}, "b", "not one of the options"},
+		{"repeated choice", entity.Question{Type: entity.QuestionTypeMultipleChoice, Options: []string{"a"}}, `["a","a"]`, "more than once"},
+		{"too many choices", entity.Question{Type: entity.QuestionTypeMultipleChoice, Options: []string{"a", "b"}, Max: bound(1)}, `["a","b"]`, "above the maximum"},
+		{"pattern", entity.Question{Type: entity.QuestionTypeText, Pattern: `^\d{3}$`}, "12a", "does not match"},
+		{"unknown type", entity.Question{Type: "essay"}, "x", "unknown type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation.ValidateContent(&tt.question, tt.content)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateContent(%q) = %v, want nil", tt.content, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateContent(%q) = %v, want error containing %q", tt.content, err, tt.wantErr)
+			}
+		})
+	}
+}
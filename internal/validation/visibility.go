@@ -0,0 +1,119 @@
+package validation
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+)
+
+// Visibility tells which questions of a form are shown for one answer
+type Visibility struct {
+	form    *entity.Form
+	answer  *entity.Answer
+	visible map[uint]bool
+	pending map[uint]bool
+}
+
+// NewVisibility evaluates the skip logic of form against the current
+// elements of answer
+func NewVisibility(form *entity.Form, answer *entity.Answer) *Visibility {
+	v := &Visibility{
+		form:    form,
+		answer:  answer,
+		visible: make(map[uint]bool, len(form.Questions)),
+		pending: make(map[uint]bool),
+	}
+
+	for _, question := range form.Questions {
+		v.IsVisible(question.OrderNumber)
+	}
+
+	return v
+}
+
+// IsVisible reports whether the question with the given order number is shown
+// A question is hidden when its rule fails; conditions on a hidden question
+// see it as unanswered, so hiding cascades down a branch. Rules that refer
+// to each other in a cycle can't be fully evaluated: a question met again
+// while its own rule is being evaluated counts as shown.
+func (v *Visibility) IsVisible(order uint) bool {
+	if visible, ok := v.visible[order]; ok {
+		return visible
+	}
+
+	question := v.form.GetQuestion(order)
+	if question == nil || question.VisibleIf == nil || v.pending[order] {
+		return true
+	}
+
+	v.pending[order] = true
+	visible := v.holds(question.VisibleIf)
+	delete(v.pending, order)
+
+	v.visible[order] = visible
+	return visible
+}
+
+func (v *Visibility) holds(rule *entity.VisibilityRule) bool {
+	if len(rule.Conditions) == 0 {
+		return true
+	}
+
+	matchAny := rule.Match == entity.MatchAny
+
+	for _, condition := range rule.Conditions {
+		ok := v.check(condition)
+		if matchAny && ok {
+			return true
+		}
+		if !matchAny && !ok {
+			return false
+		}
+	}
+
+	return !matchAny
+}
+
+func (v *Visibility) check(condition entity.Condition) bool {
+	content, answered := v.content(condition.Question)
+
+	switch condition.Operator {
+	case entity.ConditionAnswered:
+		return answered
+	case entity.ConditionNotAnswered:
+		return !answered
+	case entity.ConditionEquals:
+		return answered && content == condition.Value
+	case entity.ConditionNotEquals:
+		return !answered || content != condition.Value
+	case entity.ConditionIn:
+		return answered && slices.Contains(condition.Values, content)
+	case entity.ConditionContains:
+		var choices []string
+		if !answered || json.Unmarshal([]byte(content), &choices) != nil {
+			return false
+		}
+		return slices.Contains(choices, condition.Value)
+	default:
+		return false
+	}
+}
+
+// content returns the answer to a visible question, if there is one
+func (v *Visibility) content(order uint) (string, bool) {
+	if !v.IsVisible(order) {
+		return "", false
+	}
+
+	element := v.answer.GetElementByQuestionOrder(order)
+	if element == nil {
+		return "", false
+	}
+
+	if question := v.form.GetQuestion(order); question != nil && IsBlank(question, element.Content) {
+		return "", false
+	}
+
+	return element.Content, true
+}
@@ -0,0 +1,145 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/validation"
+)
+
+func rule(match string, conditions ...entity.Condition) *entity.VisibilityRule {
+	return &entity.VisibilityRule{Match: match, Conditions: conditions}
+}
+
+func TestVisibilityOperators(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition entity.Condition
+		content   string
+		want      bool
+	}{
+		{"equals match", entity.Condition{Operator: entity.ConditionEquals, Value: "a"}, "a", true},
+		{"equals other", entity.Condition{Operator: entity.ConditionEquals, Value: "a"}, "b", false},
+		{"equals unanswered", entity.Condition{Operator: entity.ConditionEquals, Value: "a"}, "", false},
+		{"not equals other", entity.Condition{Operator: entity.ConditionNotEquals, Value: "a"}, "b", true},
+		{"not equals match", entity.Condition{Operator: entity.ConditionNotEquals, Value: "a"}, "a", false},
+		{"not equals unanswered", entity.Condition{Operator: entity.ConditionNotEquals, Value: "a"}, "", true},
+		{"in listed", entity.Condition{Operator: entity.ConditionIn, Values: []string{"a", "b"}}, "b", true},
+		{"in not listed", entity.Condition{Operator: entity.ConditionIn, Values: []string{"a", "b"}}, "c", false},
+		{"contains chosen", entity.Condition{Operator: entity.ConditionContains, Value: "a"}, `["b","a"]`, true},
+		{"contains not chosen", entity.Condition{Operator: entity.ConditionContains, Value: "a"}, `["b"]`, false},
+		{"contains not a list", entity.Condition{Operator: entity.ConditionContains, Value: "a"}, "a", false},
+		{"answered", entity.Condition{Operator: entity.ConditionAnswered}, "x", true},
+		{"answered blank", entity.Condition{Operator: entity.ConditionAnswered}, " ", false},
+		{"not answered", entity.Condition{Operator: entity.ConditionNotAnswered}, "", true},
+		{"not answered given", entity.Condition{Operator: entity.ConditionNotAnswered}, "x", false},
+		{"unknown operator", entity.Condition{Operator: "matches"}, "x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.condition.Question = 1
+
+			form := &entity.Form{Questions: []entity.Question{
+				{OrderNumber: 1, Type: entity.QuestionTypeText},
+				{OrderNumber: 2, Type: entity.QuestionTypeText, VisibleIf: rule("", tt.condition)},
+			}}
+
+			visibility := validation.NewVisibility(form, newAnswer(false, tt.content))
+			if got := visibility.IsVisible(2); got != tt.want {
+				t.Fatalf("IsVisible = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisibilityCascades(t *testing.T) {
+	// 2 depends on 1, 3 depends on 2: hiding 2 must hide 3 even though
+	// an answer to 2 was sent
+	form := &entity.Form{Questions: []entity.Question{
+		{OrderNumber: 1, Type: entity.QuestionTypeText},
+		{OrderNumber: 2, Type: entity.QuestionTypeText, VisibleIf: rule("",
+			entity.Condition{Question: 1, Operator: entity.ConditionEquals, Value: "yes"})},
+		{OrderNumber: 3, Type: entity.QuestionTypeText, VisibleIf: rule("",
+			entity.Condition{Question: 2, Operator: entity.ConditionAnswered})},
+	}}
+
+	shown := validation.NewVisibility(form, newAnswer(false, "yes", "x"))
+	if !shown.IsVisible(2) || !shown.IsVisible(3) {
+		t.Fatalf("branch taken: visible 2=%v 3=%v, want both", shown.IsVisible(2), shown.IsVisible(3))
+	}
+
+	hidden := validation.NewVisibility(form, newAnswer(false, "no", "x"))
+	if hidden.IsVisible(2) || hidden.IsVisible(3) {
+		t.Fatalf("branch skipped: visible 2=%v 3=%v, want neither", hidden.IsVisible(2), hidden.IsVisible(3))
+	}
+}
+
+func TestVisibilityMatch(t *testing.T) {
+	conditions := []entity.Condition{
+		{Question: 1, Operator: entity.ConditionEquals, Value: "a"},
+		{Question: 2, Operator: entity.ConditionEquals, Value: "b"},
+	}
+
+	tests := []struct {
+		name    string
+		match   string
+		answers []string
+		want    bool
+	}{
+		{"all, both hold", entity.MatchAll, []string{"a", "b"}, true},
+		{"all, one holds", entity.MatchAll, []string{"a", "x"}, false},
+		{"default is all", "", []string{"a", "x"}, false},
+		{"any, one holds", entity.MatchAny, []string{"x", "b"}, true},
+		{"any, none holds", entity.MatchAny, []string{"x", "y"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := &entity.Form{Questions: []entity.Question{
+				{OrderNumber: 1, Type: entity.QuestionTypeText},
+				{OrderNumber: 2, Type: entity.QuestionTypeText},
+				{OrderNumber: 3, Type: entity.QuestionTypeText, VisibleIf: rule(tt.match, conditions...)},
+			}}
+
+			visibility := validation.NewVisibility(form, newAnswer(false, tt.answers...))
+			if got := visibility.IsVisible(3); got != tt.want {
+				t.Fatalf("IsVisible = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisibilityCycleLeavesQuestionsShown(t *testing.T) {
+	// 1 and 2 are each shown only while the other is unanswered, and 3
+	// only while itself is unanswered; a question met again while its own
+	// rule is being evaluated counts as shown
+	form := &entity.Form{Questions: []entity.Question{
+		{OrderNumber: 1, Type: entity.QuestionTypeText, VisibleIf: rule("",
+			entity.Condition{Question: 2, Operator: entity.ConditionNotAnswered})},
+		{OrderNumber: 2, Type: entity.QuestionTypeText, VisibleIf: rule("",
+			entity.Condition{Question: 1, Operator: entity.ConditionNotAnswered})},
+		{OrderNumber: 3, Type: entity.QuestionTypeText, VisibleIf: rule("",
+			entity.Condition{Question: 3, Operator: entity.ConditionNotAnswered})},
+	}}
+
+	visibility := validation.NewVisibility(form, newAnswer(false))
+
+	for _, order := range []uint{1, 2, 3} {
+		if !visibility.IsVisible(order) {
+			t.Fatalf("question %d of a cycle is hidden, want shown", order)
+		}
+	}
+}
+
+func TestVisibilityWithoutRules(t *testing.T) {
+	form := &entity.Form{Questions: []entity.Question{
+		{OrderNumber: 1, Type: entity.QuestionTypeText},
+		{OrderNumber: 2, Type: entity.QuestionTypeText, VisibleIf: rule(entity.MatchAny)},
+	}}
+
+	visibility := validation.NewVisibility(form, newAnswer(false))
+	if !visibility.IsVisible(1) || !visibility.IsVisible(2) || !visibility.IsVisible(9) {
+		t.Fatalf("questions without conditions must be shown")
+	}
+}
@@ -0,0 +1,165 @@
+// Package warmer repopulates the answer cache from the database,
+// e.g. after a Redis flush removed the answer:* keys
+package warmer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/repository"
+	"github.com/Koyo-os/answer-service/internal/service"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const DefaultBatchSize = 500
+
+type (
+	// Source streams answers from the source of truth
+	Source interface {
+		CountAnswers(context.Context, repository.AnswerFilter) (int64, error)
+		StreamAnswers(context.Context, repository.AnswerFilter, int, func([]entity.Answer) error) error
+	}
+
+	// Sink stores many cache entries at once, leaving keys that already
+	// exist untouched so newer writes and deletions made while a batch
+	// was being read are kept
+	Sink interface {
+		DoCashingBatch(context.Context, map[string]any) error
+	}
+
+	Warmer struct {
+		source Source
+		sink   Sink
+		logger *logger.Logger
+		cfg    config.Warmer
+	}
+)
+
+func NewWarmer(source Source, sink Sink, logger *logger.Logger, cfg *config.Config) *Warmer {
+	return &Warmer{
+		source: source,
+		sink:   sink,
+		logger: logger,
+		cfg:    cfg.Warmer,
+	}
+}
+
+// DefaultFilter builds the filter from the configured recent window and form IDs
+func (w *Warmer) DefaultFilter() (repository.AnswerFilter, error) {
+	filter := repository.AnswerFilter{}
+
+	if w.cfg.RecentWindow > 0 {
+		filter.Since = time.Now().Add(-w.cfg.RecentWindow)
+	}
+
+	for _, id := range w.cfg.FormIDs {
+		formID, err := uuid.Parse(id)
+		if err != nil {
+			return filter, fmt.Errorf("invalid form ID %q in warmer config: %w", id, err)
+		}
+		filter.FormIDs = append(filter.FormIDs, formID)
+	}
+
+	return filter, nil
+}
+
+// Warm loads every answer matching filter into the cache, one pipeline per batch
+// Answers already cached are not overwritten
+// Returns the number of answers read into batches
+func (w *Warmer) Warm(ctx context.Context, filter repository.AnswerFilter) (int64, error) {
+	batchSize := w.cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	total, err := w.source.CountAnswers(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count answers: %w", err)
+	}
+
+	w.logger.Info("starting cache warm-up",
+		zap.Int64("total", total),
+		zap.Int("batch_size", batchSize),
+		zap.Int("forms", len(filter.FormIDs)),
+		zap.Time("since", filter.Since))
+
+	var (
+		started = time.Now()
+		cached  int64
+	)
+
+	err = w.source.StreamAnswers(ctx, filter, batchSize, func(batch []entity.Answer) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entries := make(map[string]any, len(batch))
+		for i := range batch {
+			answer := batch[i]
+			entries[fmt.Sprintf(service.AnswerKeyTemplate, answer.ID.String())] = &answer
+		}
+
+		if err := w.sink.DoCashingBatch(ctx, entries); err != nil {
+			return fmt.Errorf("failed to cache batch: %w", err)
+		}
+
+		cached += int64(len(batch))
+
+		w.logger.Info("cache warm-up progress",
+			zap.Int64("cached", cached),
+			zap.Int64("total", total),
+			zap.Duration("elapsed", time.Since(started)))
+
+		return nil
+	})
+	if err != nil {
+		return cached, err
+	}
+
+	w.logger.Info("cache warm-up finished",
+		zap.Int64("cached", cached),
+		zap.Duration("elapsed", time.Since(started)))
+
+	return cached, nil
+}
+
+// Run warms the cache on startup and then every Interval, as configured,
+// until the context is cancelled
+func (w *Warmer) Run(ctx context.Context) {
+	if w.cfg.OnStartup {
+		w.warmDefault(ctx)
+	}
+
+	if w.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.warmDefault(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *Warmer) warmDefault(ctx context.Context) {
+	filter, err := w.DefaultFilter()
+	if err != nil {
+		w.logger.Error("invalid warmer config", zap.Error(err))
+		return
+	}
+
+	if _, err := w.Warm(ctx, filter); err != nil {
+		w.logger.Error("cache warm-up failed", zap.Error(err))
+	}
+}
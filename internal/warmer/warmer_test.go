@@ -0,0 +1,99 @@
+package warmer_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/repository"
+	"github.com/Koyo-os/answer-service/internal/service"
+	"github.com/Koyo-os/answer-service/internal/warmer"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/casher"
+	"github.com/google/uuid"
+)
+
+func answerKey(id uuid.UUID) string {
+	return fmt.Sprintf(service.AnswerKeyTemplate, id.String())
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	repo := repository.NewMemoryRepository()
+	forms := []uuid.UUID{uuid.New(), uuid.New()}
+	answers := make(map[uuid.UUID][]uuid.UUID)
+
+	for _, formID := range forms {
+		for range 3 {
+			answer := &entity.Answer{ID: uuid.New(), FormID: formID, UserID: uuid.New()}
+			answer.AddElement(1, "stored")
+			if err := repo.CreateAnswer(ctx, answer); err != nil {
+				t.Fatalf("CreateAnswer: %v", err)
+			}
+			answers[formID] = append(answers[formID], answer.ID)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		cfg    config.Warmer
+		cached []uuid.UUID
+	}{
+		{
+			name:   "every answer",
+			cfg:    config.Warmer{OnStartup: true, BatchSize: 2},
+			cached: slices.Concat(answers[forms[0]], answers[forms[1]]),
+		},
+		{
+			name:   "configured forms",
+			cfg:    config.Warmer{OnStartup: true, FormIDs: []string{forms[1].String()}},
+			cached: answers[forms[1]],
+		},
+		{
+			name: "startup disabled",
+			cfg:  config.Warmer{},
+		},
+		{
+			name: "invalid form ID",
+			cfg:  config.Warmer{OnStartup: true, FormIDs: []string{"nope"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := casher.NewMemory()
+
+			// Entries cached meanwhile are newer than the database read
+			fresh := answers[forms[0]][0]
+			if err := cache.DoCashing(ctx, answerKey(fresh), &entity.Answer{ID: fresh}); err != nil {
+				t.Fatalf("DoCashing: %v", err)
+			}
+
+			w := warmer.NewWarmer(repo, cache, logger.Get(), &config.Config{Warmer: tt.cfg})
+			w.Run(ctx)
+
+			want := []string{answerKey(fresh)}
+			for _, id := range tt.cached {
+				if id != fresh {
+					want = append(want, answerKey(id))
+				}
+			}
+
+			keys := cache.Keys()
+			slices.Sort(keys)
+			slices.Sort(want)
+			if !slices.Equal(keys, want) {
+				t.Fatalf("cached keys = %v, want %v", keys, want)
+			}
+
+			kept := new(entity.Answer)
+			if found, err := cache.Fetch(ctx, answerKey(fresh), kept, nil); err != nil || !found || len(kept.Elements) != 0 {
+				t.Fatalf("fresh entry = %+v (%v, %v), want it kept", kept, found, err)
+			}
+		})
+	}
+}
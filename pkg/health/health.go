@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 
 	"github.com/Koyo-os/answer-service/pkg/logger"
@@ -15,6 +16,7 @@ type (
 
 	HealthCheker struct {
 		healthers []Healther
+		stats     map[string]func() any
 		logger    *logger.Logger
 		server *http.Server
 	}
@@ -24,6 +26,7 @@ func NewHealthChecker(healthers ...Healther) *HealthCheker {
 	return &HealthCheker{
 		logger:    logger.Get(),
 		healthers: healthers,
+		stats:     make(map[string]func() any),
 		server: &http.Server{},
 	}
 }
@@ -48,9 +51,30 @@ func (h *HealthCheker) HeathHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// AddStats registers a named metrics source exposed on /stats
+// It must be called before RunServer
+func (h *HealthCheker) AddStats(name string, source func() any) {
+	h.stats[name] = source
+}
+
+func (h *HealthCheker) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	out := make(map[string]any, len(h.stats))
+
+	for name, source := range h.stats {
+		out[name] = source()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		h.logger.Error("error encode stats", zap.Error(err))
+	}
+}
+
 func (h *HealthCheker) RunServer(addr string) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", h.HeathHandler)
+	mux.HandleFunc("/stats", h.StatsHandler)
 	
 	h.server.Addr = addr
 	h.server.Handler = mux
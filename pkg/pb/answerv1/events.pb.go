@@ -0,0 +1,452 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: answer/v1/events.proto
+
+package answerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Event struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type            string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Timestamp       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	SchemaVersion   int32                  `protobuf:"varint,4,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	Source          string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	Subject         string                 `protobuf:"bytes,6,opt,name=subject,proto3" json:"subject,omitempty"`
+	DataContentType string                 `protobuf:"bytes,7,opt,name=data_content_type,json=dataContentType,proto3" json:"data_content_type,omitempty"`
+	Payload         []byte                 `protobuf:"bytes,8,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_answer_v1_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_answer_v1_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_answer_v1_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Event) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *Event) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Event) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Event) GetDataContentType() string {
+	if x != nil {
+		return x.DataContentType
+	}
+	return ""
+}
+
+func (x *Event) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type Answer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FormId        string                 `protobuf:"bytes,2,opt,name=form_id,json=formId,proto3" json:"form_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	IsComplete    bool                   `protobuf:"varint,4,opt,name=is_complete,json=isComplete,proto3" json:"is_complete,omitempty"`
+	Elements      []*Element             `protobuf:"bytes,5,rep,name=elements,proto3" json:"elements,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Score         *float64               `protobuf:"fixed64,8,opt,name=score,proto3,oneof" json:"score,omitempty"`
+	MaxScore      *float64               `protobuf:"fixed64,9,opt,name=max_score,json=maxScore,proto3,oneof" json:"max_score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Answer) Reset() {
+	*x = Answer{}
+	mi := &file_answer_v1_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Answer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Answer) ProtoMessage() {}
+
+func (x *Answer) ProtoReflect() protoreflect.Message {
+	mi := &file_answer_v1_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Answer.ProtoReflect.Descriptor instead.
+func (*Answer) Descriptor() ([]byte, []int) {
+	return file_answer_v1_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Answer) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Answer) GetFormId() string {
+	if x != nil {
+		return x.FormId
+	}
+	return ""
+}
+
+func (x *Answer) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Answer) GetIsComplete() bool {
+	if x != nil {
+		return x.IsComplete
+	}
+	return false
+}
+
+func (x *Answer) GetElements() []*Element {
+	if x != nil {
+		return x.Elements
+	}
+	return nil
+}
+
+func (x *Answer) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Answer) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Answer) GetScore() float64 {
+	if x != nil && x.Score != nil {
+		return *x.Score
+	}
+	return 0
+}
+
+func (x *Answer) GetMaxScore() float64 {
+	if x != nil && x.MaxScore != nil {
+		return *x.MaxScore
+	}
+	return 0
+}
+
+type Element struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	QuestionOrderNumber uint32                 `protobuf:"varint,1,opt,name=question_order_number,json=questionOrderNumber,proto3" json:"question_order_number,omitempty"`
+	Content             string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	ValueType           string                 `protobuf:"bytes,3,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`
+	Correct             *bool                  `protobuf:"varint,4,opt,name=correct,proto3,oneof" json:"correct,omitempty"`
+	Points              *float64               `protobuf:"fixed64,5,opt,name=points,proto3,oneof" json:"points,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Element) Reset() {
+	*x = Element{}
+	mi := &file_answer_v1_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Element) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Element) ProtoMessage() {}
+
+func (x *Element) ProtoReflect() protoreflect.Message {
+	mi := &file_answer_v1_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Element.ProtoReflect.Descriptor instead.
+func (*Element) Descriptor() ([]byte, []int) {
+	return file_answer_v1_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Element) GetQuestionOrderNumber() uint32 {
+	if x != nil {
+		return x.QuestionOrderNumber
+	}
+	return 0
+}
+
+func (x *Element) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Element) GetValueType() string {
+	if x != nil {
+		return x.ValueType
+	}
+	return ""
+}
+
+func (x *Element) GetCorrect() bool {
+	if x != nil && x.Correct != nil {
+		return *x.Correct
+	}
+	return false
+}
+
+func (x *Element) GetPoints() float64 {
+	if x != nil && x.Points != nil {
+		return *x.Points
+	}
+	return 0
+}
+
+type AnswerRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnswerRef) Reset() {
+	*x = AnswerRef{}
+	mi := &file_answer_v1_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnswerRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnswerRef) ProtoMessage() {}
+
+func (x *AnswerRef) ProtoReflect() protoreflect.Message {
+	mi := &file_answer_v1_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnswerRef.ProtoReflect.Descriptor instead.
+func (*AnswerRef) Descriptor() ([]byte, []int) {
+	return file_answer_v1_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AnswerRef) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_answer_v1_events_proto protoreflect.FileDescriptor
+
+const file_answer_v1_events_proto_rawDesc = "" +
+	"\n" +
+	"\x16answer/v1/events.proto\x12\tanswer.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x84\x02\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12%\n" +
+	"\x0eschema_version\x18\x04 \x01(\x05R\rschemaVersion\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12\x18\n" +
+	"\asubject\x18\x06 \x01(\tR\asubject\x12*\n" +
+	"\x11data_content_type\x18\a \x01(\tR\x0fdataContentType\x12\x18\n" +
+	"\apayload\x18\b \x01(\fR\apayload\"\xe6\x02\n" +
+	"\x06Answer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\aform_id\x18\x02 \x01(\tR\x06formId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vis_complete\x18\x04 \x01(\bR\n" +
+	"isComplete\x12.\n" +
+	"\belements\x18\x05 \x03(\v2\x12.answer.v1.ElementR\belements\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x19\n" +
+	"\x05score\x18\b \x01(\x01H\x00R\x05score\x88\x01\x01\x12 \n" +
+	"\tmax_score\x18\t \x01(\x01H\x01R\bmaxScore\x88\x01\x01B\b\n" +
+	"\x06_scoreB\f\n" +
+	"\n" +
+	"_max_score\"\xc9\x01\n" +
+	"\aElement\x122\n" +
+	"\x15question_order_number\x18\x01 \x01(\rR\x13questionOrderNumber\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x1d\n" +
+	"\n" +
+	"value_type\x18\x03 \x01(\tR\tvalueType\x12\x1d\n" +
+	"\acorrect\x18\x04 \x01(\bH\x00R\acorrect\x88\x01\x01\x12\x1b\n" +
+	"\x06points\x18\x05 \x01(\x01H\x01R\x06points\x88\x01\x01B\n" +
+	"\n" +
+	"\b_correctB\t\n" +
+	"\a_points\"\x1b\n" +
+	"\tAnswerRef\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02idB<Z:github.com/Koyo-os/answer-service/pkg/pb/answerv1;answerv1b\x06proto3"
+
+var (
+	file_answer_v1_events_proto_rawDescOnce sync.Once
+	file_answer_v1_events_proto_rawDescData []byte
+)
+
+func file_answer_v1_events_proto_rawDescGZIP() []byte {
+	file_answer_v1_events_proto_rawDescOnce.Do(func() {
+		file_answer_v1_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_answer_v1_events_proto_rawDesc), len(file_answer_v1_events_proto_rawDesc)))
+	})
+	return file_answer_v1_events_proto_rawDescData
+}
+
+var file_answer_v1_events_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_answer_v1_events_proto_goTypes = []any{
+	(*Event)(nil),                 // 0: answer.v1.Event
+	(*Answer)(nil),                // 1: answer.v1.Answer
+	(*Element)(nil),               // 2: answer.v1.Element
+	(*AnswerRef)(nil),             // 3: answer.v1.AnswerRef
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+}
+var file_answer_v1_events_proto_depIdxs = []int32{
+	4, // 0: answer.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	2, // 1: answer.v1.Answer.elements:type_name -> answer.v1.Element
+	4, // 2: answer.v1.Answer.created_at:type_name -> google.protobuf.Timestamp
+	4, // 3: answer.v1.Answer.updated_at:type_name -> google.protobuf.Timestamp
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_answer_v1_events_proto_init() }
+func file_answer_v1_events_proto_init() {
+	if File_answer_v1_events_proto != nil {
+		return
+	}
+	file_answer_v1_events_proto_msgTypes[1].OneofWrappers = []any{}
+	file_answer_v1_events_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_answer_v1_events_proto_rawDesc), len(file_answer_v1_events_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_answer_v1_events_proto_goTypes,
+		DependencyIndexes: file_answer_v1_events_proto_depIdxs,
+		MessageInfos:      file_answer_v1_events_proto_msgTypes,
+	}.Build()
+	File_answer_v1_events_proto = out.File
+	file_answer_v1_events_proto_goTypes = nil
+	file_answer_v1_events_proto_depIdxs = nil
+}
@@ -0,0 +1,4 @@
+// Package answerv1 holds the protobuf messages generated from proto/answer/v1
+package answerv1
+
+//go:generate protoc -I ../../../proto --go_out=. --go_opt=module=github.com/Koyo-os/answer-service/pkg/pb/answerv1 answer/v1/events.proto
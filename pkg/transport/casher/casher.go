@@ -2,33 +2,99 @@
 package casher
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Koyo-os/answer-service/internal/config"
 	"github.com/Koyo-os/answer-service/pkg/logger"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
-// FORM_KEY_TEMPLATE defines the format for Redis keys
-// It prefixes all form keys with "form:" to create a namespace
+// ErrCacheMiss is returned when the requested key is not present in Redis.
+// It lets callers tell an absent entry apart from a Redis failure.
+var ErrCacheMiss = errors.New("cache miss")
 
 // Casher handles caching operations using Redis as the backend
 // Note: The name could be "Cacher" for better spelling, but maintaining existing naming
 type Casher struct {
-	client *redis.Client  // Redis client for storage operations
-	logger *logger.Logger // Logger for error tracking and debugging
+	client           *redis.Client            // Redis client for storage operations
+	logger           *logger.Logger           // Logger for error tracking and debugging
+	codec            Codec                    // Codec used to encode cached values
+	defaultTTL       time.Duration            // Expiration for keys without a prefix rule
+	ttls             map[string]time.Duration // Expiration per key prefix
+	negativeTTL      time.Duration            // Expiration of "not found" markers
+	earlyRefreshBeta float64                  // XFetch beta, 0 disables early refresh
+	loadTimeout      time.Duration            // Bound of a shared load, detached from its callers
+	group            singleflight.Group       // Coalesces concurrent loads of the same key
+	durations        loadDurations            // Last load duration per key prefix
+	metrics          metrics                  // Read-through hit/miss counters
+	local            *localCache              // Optional in-process layer, nil when disabled
 }
 
 // Init creates a new Casher instance with the provided Redis client and logger
-// This is a simple constructor that doesn't require error handling
-func Init(client *redis.Client, logger *logger.Logger) *Casher {
-	return &Casher{
-		client: client,
-		logger: logger,
+// The codec and key expirations are taken from cfg.Cache
+func Init(client *redis.Client, logger *logger.Logger, cfg *config.Config) (*Casher, error) {
+	codec, err := NewCodec(cfg.Cache.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	casher := &Casher{
+		client:           client,
+		logger:           logger,
+		codec:            codec,
+		defaultTTL:       cfg.Cache.DefaultTTL,
+		ttls:             cfg.Cache.TTLs,
+		negativeTTL:      cfg.Cache.NegativeTTL,
+		earlyRefreshBeta: cfg.Cache.EarlyRefreshBeta,
+		loadTimeout:      cfg.Cache.LoadTimeout,
 	}
+
+	if casher.loadTimeout <= 0 {
+		casher.loadTimeout = DefaultLoadTimeout
+	}
+
+	if local := cfg.Cache.Local; local.Enabled {
+		if local.Size <= 0 {
+			return nil, fmt.Errorf("local cache size must be positive, got %d", local.Size)
+		}
+		if local.TTL <= 0 {
+			return nil, fmt.Errorf("local cache TTL must be positive, got %s", local.TTL)
+		}
+
+		casher.local = newLocalCache(client, logger, local.Size, local.TTL, local.InvalidationChannel)
+	}
+
+	return casher, nil
+}
+
+// TTLFor returns the expiration applied to key
+// The longest matching prefix wins; zero means the key never expires
+func (c *Casher) TTLFor(key string) time.Duration {
+	ttl, matched := c.defaultTTL, 0
+
+	for prefix, prefixTTL := range c.ttls {
+		if len(prefix) > matched && strings.HasPrefix(key, prefix) {
+			ttl, matched = prefixTTL, len(prefix)
+		}
+	}
+
+	return ttl
 }
 
 func (c *Casher) Close() error {
+	if c.local != nil {
+		if err := c.local.close(); err != nil {
+			c.logger.Error("error close cash invalidation subscription", zap.Error(err))
+		}
+	}
+
 	return c.client.Close()
 }
 
@@ -45,20 +111,62 @@ func (c *Casher) DeleteFromCash(ctx context.Context, key string) error {
 			zap.Error(res.Err()))
 	}
 
+	if c.local != nil {
+		c.local.invalidate(ctx, key)
+	}
+
 	return nil
 }
 
-// AddToCash stores a payload in Redis using the provided key
-// The payload is stored with no expiration time (persistence until explicit deletion)
+// DeleteFromCashBatch removes many keys in a single pipeline
+// Each key gets its own DEL, as a multi-key DEL fails on Redis Cluster
+// when the keys hash to different slots
+func (c *Casher) DeleteFromCashBatch(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pipe := c.client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logger.Error("error delete batch from redis",
+			zap.Int("size", len(keys)),
+			zap.Error(err))
+		return err
+	}
+
+	if c.local != nil {
+		for _, key := range keys {
+			c.local.invalidate(ctx, key)
+		}
+	}
+
+	return nil
+}
+
+// DoCashing encodes a payload with the configured codec and stores it in Redis
+// The entry expires after the TTL configured for the key prefix
 // Parameters:
 //   - ctx: Context for cancellation and timeouts
-//   - key: Unique identifier for the form data
-//   - payload: Raw bytes of the data to be cached
+//   - key: Unique identifier for the cached data
+//   - payload: Value to be cached (must be encodable by the codec)
 //
-// Returns an error if the Redis operation fails
+// Returns an error if encoding or the Redis operation fails
 func (c *Casher) DoCashing(ctx context.Context, key string, payload any) error {
-	// Format the key using the template and store the payload
-	res := c.client.Set(ctx, key, payload, 0)
+	data, err := c.codec.Marshal(payload)
+	if err != nil {
+		c.logger.Error("failed to encode payload for cash",
+			zap.String("key", key),
+			zap.String("codec", c.codec.Name()),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	res := c.client.Set(ctx, key, data, c.TTLFor(key))
 
 	if err := res.Err(); err != nil {
 		c.logger.Error("failed to cash payload with",
@@ -68,9 +176,122 @@ func (c *Casher) DoCashing(ctx context.Context, key string, payload any) error {
 		return err
 	}
 
+	// Other replicas may hold the previous value locally
+	if c.local != nil {
+		c.local.invalidate(ctx, key)
+		c.local.set(key, data)
+	}
+
+	return nil
+}
+
+// DoCashingBatch encodes and stores many payloads in a single pipeline,
+// only filling keys that are missing from Redis (SET NX). Bulk loads such as
+// the warmer read the database before writing, so a key set meanwhile holds
+// a newer value, or the "not found" marker left by MarkDeleted, and must win.
+// Each entry expires after the TTL configured for its key prefix
+// Returns an error if any payload can't be encoded or the pipeline fails
+func (c *Casher) DoCashingBatch(ctx context.Context, entries map[string]any) error {
+	pipe := c.client.Pipeline()
+	cmds := make(map[string]*redis.BoolCmd, len(entries))
+
+	for key, payload := range entries {
+		data, err := c.codec.Marshal(payload)
+		if err != nil {
+			c.logger.Error("failed to encode payload for cash",
+				zap.String("key", key),
+				zap.String("codec", c.codec.Name()),
+				zap.Error(err),
+			)
+			return fmt.Errorf("failed to encode payload for %s: %w", key, err)
+		}
+
+		cmds[key] = pipe.SetNX(ctx, key, data, c.TTLFor(key))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logger.Error("failed to cash batch",
+			zap.Int("size", len(entries)),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	// Replicas may still hold a local copy of a key that expired from Redis
+	if c.local != nil {
+		for key, cmd := range cmds {
+			if cmd.Val() {
+				c.local.invalidate(ctx, key)
+			}
+		}
+	}
+
 	return nil
 }
 
+// MarkDeleted replaces keys with the "not found" marker for the negative TTL,
+// so reads miss and DoCashingBatch can't bring back a value deleted after it
+// was read from the database
+func (c *Casher) MarkDeleted(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pipe := c.client.Pipeline()
+	for _, key := range keys {
+		pipe.Set(ctx, key, tombstone, c.negativeTTL)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logger.Error("failed to mark cash entries deleted",
+			zap.Int("size", len(keys)),
+			zap.Error(err))
+		return err
+	}
+
+	if c.local != nil {
+		for _, key := range keys {
+			c.local.invalidate(ctx, key)
+		}
+	}
+
+	return nil
+}
+
+// Version returns the counter stored at key, or 0 if it was never bumped
+// Callers put the version in the keys of derived entries, so bumping it
+// retires every entry computed before, including ones still being computed
+func (c *Casher) Version(ctx context.Context, key string) (int64, error) {
+	version, err := c.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		c.metrics.errors.Add(1)
+		c.logger.Error("failed to read cash version",
+			zap.String("key", key),
+			zap.Error(err))
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// BumpVersion increments the counter stored at key and returns its new value
+// The counter never expires, so a version is never reused
+func (c *Casher) BumpVersion(ctx context.Context, key string) (int64, error) {
+	version, err := c.client.Incr(ctx, key).Result()
+	if err != nil {
+		c.metrics.errors.Add(1)
+		c.logger.Error("failed to bump cash version",
+			zap.String("key", key),
+			zap.Error(err))
+		return 0, err
+	}
+
+	return version, nil
+}
+
 // GetCashFor retrieves cached data from Redis for the specified key
 // Parameters:
 //   - ctx: Context for cancellation and timeouts
@@ -78,15 +299,29 @@ func (c *Casher) DoCashing(ctx context.Context, key string, payload any) error {
 //
 // Returns:
 //   - []byte: The cached data if found
-//   - error: Error if the retrieval fails or the key doesn't exist
+//   - error: ErrCacheMiss if the key doesn't exist, or the Redis error
 //
-// The function handles two potential error cases:
-//  1. Redis operation failure
-//  2. Byte conversion failure
+// The function handles three potential error cases:
+//  1. Missing key or cached "not found" marker (reported as ErrCacheMiss, not logged)
+//  2. Redis operation failure
+//  3. Byte conversion failure
 func (c *Casher) GetCashFor(ctx context.Context, key string) ([]byte, error) {
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			if bytes.Equal(data, tombstone) {
+				return nil, ErrCacheMiss
+			}
+			return data, nil
+		}
+	}
+
 	// Attempt to retrieve the data from Redis
 	res := c.client.Get(ctx, key)
 	if err := res.Err(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrCacheMiss
+		}
+
 		c.logger.Error("error get cash",
 			zap.String("key", key),
 			zap.Error(err),
@@ -104,5 +339,39 @@ func (c *Casher) GetCashFor(ctx context.Context, key string) ([]byte, error) {
 		return nil, err
 	}
 
+	if c.local != nil {
+		c.local.set(key, data)
+	}
+
+	if bytes.Equal(data, tombstone) {
+		return nil, ErrCacheMiss
+	}
+
 	return data, nil
 }
+
+// Get retrieves the value stored under key and decodes it into a new T
+// Returns ErrCacheMiss if the key doesn't exist
+func Get[T any](ctx context.Context, c *Casher, key string) (*T, error) {
+	data, err := c.GetCashFor(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	value := new(T)
+	if err := c.codec.Unmarshal(data, value); err != nil {
+		c.logger.Error("failed to decode cashed payload",
+			zap.String("key", key),
+			zap.String("codec", c.codec.Name()),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	return value, nil
+}
+
+// Set encodes value and stores it under key with the key's TTL
+func Set[T any](ctx context.Context, c *Casher, key string, value *T) error {
+	return c.DoCashing(ctx, key, value)
+}
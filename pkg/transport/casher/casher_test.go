@@ -0,0 +1,73 @@
+package casher_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/casher"
+	"github.com/redis/go-redis/v9"
+)
+
+// newCasher builds a Casher whose Redis is never reached
+func newCasher(t *testing.T, cache config.Cache) (*casher.Casher, error) {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	return casher.Init(client, logger.Get(), &config.Config{Cache: cache})
+}
+
+func TestTTLFor(t *testing.T) {
+	c, err := newCasher(t, config.Cache{
+		DefaultTTL: time.Hour,
+		TTLs: map[string]time.Duration{
+			"answer:":       10 * time.Minute,
+			"answer:stats:": time.Minute,
+			"form:":         0,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want time.Duration
+	}{
+		{"answer:123", 10 * time.Minute},
+		{"answer:stats:123", time.Minute},
+		{"form:123", 0},
+		{"stats:123:1", time.Hour},
+		{"answers", time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := c.TTLFor(tt.key); got != tt.want {
+				t.Fatalf("TTLFor(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitRejectsInvalidLocalCache(t *testing.T) {
+	tests := []struct {
+		name  string
+		local config.LocalCache
+	}{
+		{"no size", config.LocalCache{Enabled: true, TTL: time.Second}},
+		{"no TTL", config.LocalCache{Enabled: true, Size: 100}},
+		{"negative TTL", config.LocalCache{Enabled: true, Size: 100, TTL: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newCasher(t, config.Cache{Local: tt.local}); err == nil {
+				t.Fatalf("Init succeeded, want an error")
+			}
+		})
+	}
+}
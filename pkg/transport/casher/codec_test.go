@@ -0,0 +1,152 @@
+package casher_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/pkg/transport/casher"
+	"github.com/google/uuid"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	score, correct := 2.0, true
+
+	tests := []struct {
+		name  string
+		value any
+		dest  func() any
+	}{
+		{
+			name: "form",
+			value: &entity.Form{
+				ID:             uuid.New(),
+				ResponsePolicy: entity.ResponsePolicySingle,
+				MaxResponses:   10,
+				Questions: []entity.Question{
+					{OrderNumber: 1, Type: entity.QuestionTypeSingleChoice, Options: []string{"a", "b"}, Correct: []string{"b"}, Points: 2},
+				},
+			},
+			dest: func() any { return new(entity.Form) },
+		},
+		{
+			name: "scored answer",
+			value: &entity.Answer{
+				ID:         uuid.New(),
+				FormID:     uuid.New(),
+				UserID:     uuid.New(),
+				IsComplete: true,
+				Score:      &score,
+				MaxScore:   &score,
+				Elements: []entity.Element{
+					{QuestionOrderNumber: 1, ValueType: entity.ValueTypeString, Content: "b", Correct: &correct, Points: &score},
+				},
+			},
+			dest: func() any { return new(entity.Answer) },
+		},
+		{
+			name:  "map",
+			value: &map[string]int{"a": 1, "b": 2},
+			dest:  func() any { return new(map[string]int) },
+		},
+	}
+
+	for _, name := range []string{casher.CodecJSON, casher.CodecMsgpack} {
+		codec, err := casher.NewCodec(name)
+		if err != nil {
+			t.Fatalf("NewCodec(%s): %v", name, err)
+		}
+		if codec.Name() != name {
+			t.Fatalf("NewCodec(%s).Name() = %s", name, codec.Name())
+		}
+
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				data, err := codec.Marshal(tt.value)
+				if err != nil {
+					t.Fatalf("Marshal: %v", err)
+				}
+
+				got := tt.dest()
+				if err := codec.Unmarshal(data, got); err != nil {
+					t.Fatalf("Unmarshal: %v", err)
+				}
+
+				// Compared through JSON, which both sides share whatever the codec
+				want, _ := json.Marshal(tt.value)
+				have, _ := json.Marshal(got)
+				if string(have) != string(want) {
+					t.Fatalf("round trip = %s, want %s", have, want)
+				}
+			})
+		}
+	}
+
+	if _, err := casher.NewCodec("gob"); err == nil {
+		t.Fatalf("NewCodec(gob) succeeded, want an error")
+	}
+}
+
+func TestCodecsKeepTypedValues(t *testing.T) {
+	answer := &entity.Answer{ID: uuid.New(), FormID: uuid.New(), IsComplete: true}
+
+	values := []struct {
+		valueType string
+		value     any
+	}{
+		{entity.ValueTypeString, "text"},
+		{entity.ValueTypeNumber, 4.5},
+		{entity.ValueTypeBool, true},
+		{entity.ValueTypeDate, "2024-02-29"},
+		{entity.ValueTypeList, []string{"a", "b"}},
+		{entity.ValueTypeJSON, map[string]int{"x": 1}},
+	}
+	for i, v := range values {
+		element := entity.Element{QuestionOrderNumber: uint(i + 1)}
+		if err := element.SetValue(v.valueType, v.value); err != nil {
+			t.Fatalf("SetValue(%s): %v", v.valueType, err)
+		}
+		answer.Elements = append(answer.Elements, element)
+	}
+
+	for _, name := range []string{casher.CodecJSON, casher.CodecMsgpack} {
+		t.Run(name, func(t *testing.T) {
+			codec, err := casher.NewCodec(name)
+			if err != nil {
+				t.Fatalf("NewCodec: %v", err)
+			}
+
+			data, err := codec.Marshal(answer)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+
+			got := new(entity.Answer)
+			if err := codec.Unmarshal(data, got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+
+			if len(got.Elements) != len(answer.Elements) {
+				t.Fatalf("got %d elements, want %d", len(got.Elements), len(answer.Elements))
+			}
+			for i, want := range answer.Elements {
+				element := got.Elements[i]
+				if element.ValueType != want.ValueType || element.Content != want.Content {
+					t.Errorf("element %d = %s %q, want %s %q", i, element.ValueType, element.Content, want.ValueType, want.Content)
+				}
+				if !reflect.DeepEqual(element.Value(), want.Value()) {
+					t.Errorf("element %d value = %#v, want %#v", i, element.Value(), want.Value())
+				}
+			}
+
+			if number := got.Elements[1].NumberValue; number == nil || *number != 4.5 {
+				t.Errorf("NumberValue = %v, want 4.5", number)
+			}
+			if date := got.Elements[3].DateValue; date == nil || !date.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("DateValue = %v, want 2024-02-29", date)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package casher
+
+import "time"
+
+// ShouldRefreshEarly exposes the XFetch decision to tests
+func (c *Casher) ShouldRefreshEarly(key string, ttl time.Duration) bool {
+	return c.shouldRefreshEarly(key, ttl)
+}
+
+// SetLoadDuration records how long loads of keys with prefix took
+func (c *Casher) SetLoadDuration(prefix string, d time.Duration) {
+	c.durations.set(prefix, d)
+}
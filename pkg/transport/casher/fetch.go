@@ -0,0 +1,285 @@
+package casher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// tombstone is stored in place of a value when the loader reported that
+// nothing exists for the key. Neither codec ever produces these bytes
+// for a struct, so it can't collide with a real cached value.
+var tombstone = []byte("\x00casher:not-found")
+
+// DefaultLoadTimeout bounds a shared load when the config sets no LoadTimeout
+const DefaultLoadTimeout = 30 * time.Second
+
+// Stats is a snapshot of the read-through cache counters
+type Stats struct {
+	LocalHits      uint64 `json:"local_hits"`
+	Hits           uint64 `json:"hits"`
+	Misses         uint64 `json:"misses"`
+	NegativeHits   uint64 `json:"negative_hits"`
+	Loads          uint64 `json:"loads"`
+	Coalesced      uint64 `json:"coalesced"`
+	EarlyRefreshes uint64 `json:"early_refreshes"`
+	Errors         uint64 `json:"errors"`
+}
+
+// metrics holds the live counters behind Stats
+type metrics struct {
+	localHits      atomic.Uint64
+	hits           atomic.Uint64
+	misses         atomic.Uint64
+	negativeHits   atomic.Uint64
+	loads          atomic.Uint64
+	coalesced      atomic.Uint64
+	earlyRefreshes atomic.Uint64
+	errors         atomic.Uint64
+}
+
+// loadDurations remembers how long loads take per key prefix, which is
+// the "delta" used to decide on probabilistic early refresh
+type loadDurations struct {
+	mu        sync.RWMutex
+	durations map[string]time.Duration
+}
+
+func (l *loadDurations) get(prefix string) time.Duration {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.durations[prefix]
+}
+
+func (l *loadDurations) set(prefix string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.durations == nil {
+		l.durations = make(map[string]time.Duration)
+	}
+	l.durations[prefix] = d
+}
+
+// Stats returns the current read-through cache counters
+func (c *Casher) Stats() Stats {
+	return Stats{
+		LocalHits:      c.metrics.localHits.Load(),
+		Hits:           c.metrics.hits.Load(),
+		Misses:         c.metrics.misses.Load(),
+		NegativeHits:   c.metrics.negativeHits.Load(),
+		Loads:          c.metrics.loads.Load(),
+		Coalesced:      c.metrics.coalesced.Load(),
+		EarlyRefreshes: c.metrics.earlyRefreshes.Load(),
+		Errors:         c.metrics.errors.Load(),
+	}
+}
+
+// Fetch reads key into dest, calling load on a miss and caching its result
+// Concurrent misses for the same key share a single load, entries close to
+// expiry are refreshed early with a probability that grows as the TTL runs
+// out, and "not found" results are cached for the negative TTL
+// Parameters:
+//   - ctx: Context for cancellation and timeouts of this call; a load
+//     runs detached from it, bounded by the configured load timeout
+//   - key: Unique identifier for the cached data
+//   - dest: Pointer the value is decoded into
+//   - load: Called when the value isn't cached; returns (nil, nil) when
+//     the value doesn't exist, which is remembered for the negative TTL
+//
+// Returns false if the value doesn't exist, or an error if loading or
+// decoding fails. Redis failures are logged and fall back to load.
+func (c *Casher) Fetch(ctx context.Context, key string, dest any, load func(context.Context) (any, error)) (bool, error) {
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			if bytes.Equal(data, tombstone) {
+				c.metrics.negativeHits.Add(1)
+				return false, nil
+			}
+
+			c.metrics.localHits.Add(1)
+			return true, c.codec.Unmarshal(data, dest)
+		}
+	}
+
+	data, ttl, err := c.lookup(ctx, key)
+
+	switch {
+	case err == nil:
+		if bytes.Equal(data, tombstone) {
+			c.metrics.negativeHits.Add(1)
+			return false, nil
+		}
+
+		if !c.shouldRefreshEarly(key, ttl) {
+			c.metrics.hits.Add(1)
+			if c.local != nil {
+				c.local.set(key, data)
+			}
+			return true, c.codec.Unmarshal(data, dest)
+		}
+
+		c.metrics.earlyRefreshes.Add(1)
+	case errors.Is(err, ErrCacheMiss):
+		c.metrics.misses.Add(1)
+	default:
+		c.metrics.errors.Add(1)
+		c.logger.Warn("cash lookup failed, falling back to loader",
+			zap.String("key", key),
+			zap.Error(err))
+	}
+
+	// The load is shared by every caller missing key meanwhile, so it runs
+	// under its own deadline: the first caller giving up must not fail it
+	// for the others. Each caller still stops waiting when its ctx is done.
+	results := c.group.DoChan(key, func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.loadTimeout)
+		defer cancel()
+
+		return c.loadAndStore(loadCtx, key, load)
+	})
+
+	var res singleflight.Result
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case res = <-results:
+	}
+
+	if res.Shared {
+		c.metrics.coalesced.Add(1)
+	}
+	if res.Err != nil {
+		return false, res.Err
+	}
+
+	loaded := res.Val.([]byte)
+	if loaded == nil {
+		return false, nil
+	}
+
+	return true, c.codec.Unmarshal(loaded, dest)
+}
+
+// GetOrLoad is the typed form of Fetch
+// It returns nil without an error when the value doesn't exist
+func GetOrLoad[T any](ctx context.Context, c *Casher, key string, load func(context.Context) (*T, error)) (*T, error) {
+	value := new(T)
+
+	found, err := c.Fetch(ctx, key, value, func(ctx context.Context) (any, error) {
+		loaded, err := load(ctx)
+		if err != nil || loaded == nil {
+			return nil, err
+		}
+		return loaded, nil
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// lookup reads the raw value and its remaining TTL in one round trip
+func (c *Casher) lookup(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	pipe := c.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+
+	// A miss is reported per command below, but a failed round trip
+	// leaves the commands without an error of their own
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+
+	data, err := get.Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, 0, ErrCacheMiss
+		}
+		return nil, 0, err
+	}
+
+	return data, pttl.Val(), nil
+}
+
+// loadAndStore runs the loader and writes its encoded result (or a
+// tombstone) to Redis. It returns the encoded bytes, nil meaning "not found".
+func (c *Casher) loadAndStore(ctx context.Context, key string, load func(context.Context) (any, error)) (any, error) {
+	c.metrics.loads.Add(1)
+
+	start := time.Now()
+	value, err := load(ctx)
+	c.durations.set(keyPrefix(key), time.Since(start))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		if c.local != nil {
+			c.local.set(key, tombstone)
+		}
+
+		if err := c.client.Set(ctx, key, tombstone, c.negativeTTL).Err(); err != nil {
+			c.metrics.errors.Add(1)
+			c.logger.Error("failed to cash not-found marker",
+				zap.String("key", key),
+				zap.Error(err))
+		}
+		return []byte(nil), nil
+	}
+
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.local != nil {
+		c.local.set(key, data)
+	}
+
+	if err := c.client.Set(ctx, key, data, c.TTLFor(key)).Err(); err != nil {
+		c.metrics.errors.Add(1)
+		c.logger.Error("failed to cash loaded payload",
+			zap.String("key", key),
+			zap.Error(err))
+	}
+
+	return data, nil
+}
+
+// shouldRefreshEarly implements XFetch: refresh when
+// delta * beta * -ln(rand) reaches the remaining TTL, where delta is the
+// last observed load duration for the key's prefix
+func (c *Casher) shouldRefreshEarly(key string, ttl time.Duration) bool {
+	if c.earlyRefreshBeta <= 0 || ttl <= 0 {
+		return false
+	}
+
+	delta := c.durations.get(keyPrefix(key))
+	if delta <= 0 {
+		return false
+	}
+
+	return float64(delta)*c.earlyRefreshBeta*-math.Log(rand.Float64()) >= float64(ttl)
+}
+
+// keyPrefix returns the namespace of a key, e.g. "answer:" for "answer:<id>"
+func keyPrefix(key string) string {
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		return key[:i+1]
+	}
+	return key
+}
@@ -0,0 +1,146 @@
+package casher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/pkg/transport/casher"
+)
+
+type cached struct {
+	Name string `json:"name"`
+}
+
+func TestFetchSharesConcurrentLoads(t *testing.T) {
+	c, err := newCasher(t, config.Cache{})
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	const callers = 10
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (any, error) {
+		loads.Add(1)
+		<-release
+		return &cached{Name: "shared"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan string, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			dest := new(cached)
+			if found, err := c.Fetch(context.Background(), "form:1", dest, load); err != nil || !found {
+				t.Errorf("Fetch = %v, %v, want found", found, err)
+			}
+			results <- dest.Name
+		}()
+	}
+
+	// Every caller has failed its Redis lookup once it waits on the load
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Errors < callers && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for name := range results {
+		if name != "shared" {
+			t.Fatalf("Fetch decoded %q, want the shared load", name)
+		}
+	}
+	if got := loads.Load(); got != 1 {
+		t.Fatalf("loader ran %d times, want 1", got)
+	}
+	if stats := c.Stats(); stats.Loads != 1 || stats.Coalesced != callers {
+		t.Fatalf("stats = %+v, want 1 load shared by %d callers", stats, callers)
+	}
+}
+
+func TestFetchCachesResults(t *testing.T) {
+	errLoad := errors.New("database is down")
+
+	tests := []struct {
+		name       string
+		value      any
+		err        error
+		found      bool
+		loadsTwice bool
+	}{
+		{name: "value", value: &cached{Name: "form"}, found: true},
+		{name: "not found", value: nil},
+		{name: "error", err: errLoad, loadsTwice: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := casher.NewMemory()
+
+			loads := 0
+			load := func(context.Context) (any, error) {
+				loads++
+				return tt.value, tt.err
+			}
+
+			for range 2 {
+				found, err := m.Fetch(context.Background(), "form:1", new(cached), load)
+				if !errors.Is(err, tt.err) || found != tt.found {
+					t.Fatalf("Fetch = %v, %v, want %v, %v", found, err, tt.found, tt.err)
+				}
+			}
+
+			want := 1
+			if tt.loadsTwice {
+				want = 2
+			}
+			if loads != want {
+				t.Fatalf("loader ran %d times, want %d", loads, want)
+			}
+		})
+	}
+}
+
+func TestShouldRefreshEarly(t *testing.T) {
+	tests := []struct {
+		name  string
+		beta  float64
+		delta time.Duration
+		ttl   time.Duration
+		want  bool
+	}{
+		{name: "disabled", beta: 0, delta: time.Hour, ttl: time.Nanosecond},
+		{name: "no expiry", beta: 1, delta: time.Hour, ttl: 0},
+		{name: "never loaded", beta: 1, ttl: time.Nanosecond},
+		{name: "far from expiry", beta: 1, delta: time.Nanosecond, ttl: time.Hour},
+		{name: "about to expire", beta: 1, delta: time.Hour, ttl: time.Nanosecond, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newCasher(t, config.Cache{EarlyRefreshBeta: tt.beta})
+			if err != nil {
+				t.Fatalf("Init: %v", err)
+			}
+			if tt.delta > 0 {
+				c.SetLoadDuration("form:", tt.delta)
+			}
+
+			if got := c.ShouldRefreshEarly("form:1", tt.ttl); got != tt.want {
+				t.Fatalf("ShouldRefreshEarly = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
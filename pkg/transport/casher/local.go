@@ -0,0 +1,90 @@
+package casher
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// invalidationSeparator splits the sender instance ID from the key in
+// invalidation messages ("<instance>|<key>")
+const invalidationSeparator = "|"
+
+// localCache is the optional in-process layer in front of Redis
+// It keeps encoded values so local hits are decoded exactly like Redis hits,
+// and listens on a pub/sub channel so every replica drops a key as soon
+// as any replica changes or deletes it
+type localCache struct {
+	entries    *expirable.LRU[string, []byte] // Bounded entries with per-entry expiration
+	client     *redis.Client                  // Redis client used to publish invalidations
+	pubsub     *redis.PubSub                  // Subscription to invalidations from other replicas
+	channel    string                         // Pub/sub channel name
+	instanceID string                         // Identifies this replica's own messages
+	logger     *logger.Logger
+}
+
+// newLocalCache creates the local layer and subscribes to the invalidation channel
+func newLocalCache(client *redis.Client, logger *logger.Logger, size int, ttl time.Duration, channel string) *localCache {
+	l := &localCache{
+		entries:    expirable.NewLRU[string, []byte](size, nil, ttl),
+		client:     client,
+		pubsub:     client.Subscribe(context.Background(), channel),
+		channel:    channel,
+		instanceID: uuid.New().String(),
+		logger:     logger,
+	}
+
+	go l.listen()
+
+	return l
+}
+
+// get returns the locally cached bytes for key
+func (l *localCache) get(key string) ([]byte, bool) {
+	return l.entries.Get(key)
+}
+
+// set stores data for key in this replica only
+func (l *localCache) set(key string, data []byte) {
+	l.entries.Add(key, data)
+}
+
+// invalidate drops key locally and tells the other replicas to do the same
+func (l *localCache) invalidate(ctx context.Context, key string) {
+	l.entries.Remove(key)
+
+	msg := l.instanceID + invalidationSeparator + key
+	if err := l.client.Publish(ctx, l.channel, msg).Err(); err != nil {
+		l.logger.Error("failed to publish cash invalidation",
+			zap.String("key", key),
+			zap.String("channel", l.channel),
+			zap.Error(err))
+	}
+}
+
+// listen applies invalidations published by other replicas until the
+// subscription is closed
+func (l *localCache) listen() {
+	for msg := range l.pubsub.Channel() {
+		sender, key, ok := strings.Cut(msg.Payload, invalidationSeparator)
+		if !ok || sender == l.instanceID {
+			continue
+		}
+
+		l.entries.Remove(key)
+
+		l.logger.Debug("applied remote cash invalidation",
+			zap.String("key", key),
+			zap.String("sender", sender))
+	}
+}
+
+func (l *localCache) close() error {
+	return l.pubsub.Close()
+}
@@ -0,0 +1,176 @@
+package casher
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// Memory is an in-process stand-in for Casher
+// It stores values encoded with the JSON codec and supports the same
+// read-through semantics as Casher.Fetch, including cached "not found"
+// markers, but never expires entries. It is meant for tests and local runs.
+type Memory struct {
+	mu       sync.RWMutex
+	codec    Codec
+	entries  map[string][]byte
+	versions map[string]int64
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		codec:    JSONCodec{},
+		entries:  make(map[string][]byte),
+		versions: make(map[string]int64),
+	}
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+func (m *Memory) IsHealthy() bool {
+	return true
+}
+
+func (m *Memory) DoCashing(_ context.Context, key string, payload any) error {
+	data, err := m.codec.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.entries[key] = data
+	m.mu.Unlock()
+
+	return nil
+}
+
+// DoCashingBatch only fills missing keys, like Casher.DoCashingBatch
+func (m *Memory) DoCashingBatch(_ context.Context, entries map[string]any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, payload := range entries {
+		if _, ok := m.entries[key]; ok {
+			continue
+		}
+
+		data, err := m.codec.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		m.entries[key] = data
+	}
+
+	return nil
+}
+
+// MarkDeleted stores the "not found" marker for keys, like Casher.MarkDeleted
+func (m *Memory) MarkDeleted(_ context.Context, keys []string) error {
+	m.mu.Lock()
+	for _, key := range keys {
+		m.entries[key] = tombstone
+	}
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *Memory) DeleteFromCash(_ context.Context, key string) error {
+	m.mu.Lock()
+	delete(m.entries, key)
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *Memory) DeleteFromCashBatch(_ context.Context, keys []string) error {
+	m.mu.Lock()
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Version returns the counter stored at key, like Casher.Version
+func (m *Memory) Version(_ context.Context, key string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.versions[key], nil
+}
+
+// BumpVersion increments the counter stored at key, like Casher.BumpVersion
+func (m *Memory) BumpVersion(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.versions[key]++
+	return m.versions[key], nil
+}
+
+// GetCashFor returns the stored bytes or ErrCacheMiss, like Casher.GetCashFor
+func (m *Memory) GetCashFor(_ context.Context, key string) ([]byte, error) {
+	m.mu.RLock()
+	data, ok := m.entries[key]
+	m.mu.RUnlock()
+
+	if !ok || bytes.Equal(data, tombstone) {
+		return nil, ErrCacheMiss
+	}
+
+	return data, nil
+}
+
+// Fetch reads key into dest, calling load on a miss, like Casher.Fetch
+func (m *Memory) Fetch(ctx context.Context, key string, dest any, load func(context.Context) (any, error)) (bool, error) {
+	m.mu.RLock()
+	data, ok := m.entries[key]
+	m.mu.RUnlock()
+
+	if ok {
+		if bytes.Equal(data, tombstone) {
+			return false, nil
+		}
+		return true, m.codec.Unmarshal(data, dest)
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if value == nil {
+		m.mu.Lock()
+		m.entries[key] = tombstone
+		m.mu.Unlock()
+
+		return false, nil
+	}
+
+	if data, err = m.codec.Marshal(value); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	m.entries[key] = data
+	m.mu.Unlock()
+
+	return true, m.codec.Unmarshal(data, dest)
+}
+
+// Keys returns the keys currently stored, useful for assertions in tests
+func (m *Memory) Keys() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]string, 0, len(m.entries))
+	for key := range m.entries {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -2,7 +2,6 @@
 package consumer
 
 import (
-	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
@@ -10,6 +9,7 @@ import (
 	"github.com/Koyo-os/answer-service/internal/config"
 	"github.com/Koyo-os/answer-service/internal/entity"
 	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/envelope"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
@@ -24,22 +24,42 @@ const (
 	DEFAULT_RETRY_ATTEMPTS  = 3
 )
 
+// channel is the part of *amqp.Channel the consumer uses
+type channel interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Close() error
+}
+
 // Consumer represents a RabbitMQ consumer client
 // It maintains connection, channel, and configuration details needed for message consumption
 type Consumer struct {
 	conn         *amqp.Connection // RabbitMQ connection instance
-	channel      *amqp.Channel    // Channel for communication with RabbitMQ
+	channel      channel          // Channel for communication with RabbitMQ
 	logger       *logger.Logger   // Logger instance for error and info logging
 	cfg          *config.Config   // Configuration settings
+	queue        string           // Queue consumed from
 	exchanges    map[string]bool  // Track declared exchanges
 	mu           sync.RWMutex     // Mutex for thread-safe operations
 	isConnected  bool             // Connection status flag
 	reconnecting bool             // Reconnection status flag
 }
 
-// Init creates and initializes a new Consumer instance
+// Init creates and initializes a new Consumer instance reading request events
 // Returns an error if the channel creation fails
 func Init(cfg *config.Config, logger *logger.Logger, conn *amqp.Connection) (*Consumer, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("invalid parameters: cfg cannot be nil")
+	}
+
+	return InitQueue(cfg, logger, conn, cfg.Exchanges["request"], cfg.Queues["request"])
+}
+
+// InitQueue creates a Consumer reading queue, declaring exchange for it
+// Bind the queue to routing keys with Subscribe before consuming
+func InitQueue(cfg *config.Config, logger *logger.Logger, conn *amqp.Connection, exchange, queue string) (*Consumer, error) {
 	if cfg == nil || logger == nil || conn == nil {
 		return nil, fmt.Errorf("invalid parameters: cfg, logger, and conn cannot be nil")
 	}
@@ -48,6 +68,7 @@ func Init(cfg *config.Config, logger *logger.Logger, conn *amqp.Connection) (*Co
 		conn:        conn,
 		logger:      logger,
 		cfg:         cfg,
+		queue:       queue,
 		exchanges:   make(map[string]bool),
 		isConnected: true,
 	}
@@ -56,7 +77,11 @@ func Init(cfg *config.Config, logger *logger.Logger, conn *amqp.Connection) (*Co
 		return nil, fmt.Errorf("failed to initialize channel: %w", err)
 	}
 
-	if err := consumer.declareExchange(cfg.Exchanges["request"]); err != nil {
+	consumer.mu.Lock()
+	err := consumer.declareExchange(exchange)
+	consumer.mu.Unlock()
+
+	if err != nil {
 		consumer.cleanup()
 		return nil, fmt.Errorf("failed to declare exchange: %w", err)
 	}
@@ -77,6 +102,7 @@ func (c *Consumer) initializeChannel() error {
 }
 
 // declareExchange declares an exchange and tracks it
+// The caller must hold the write lock
 func (c *Consumer) declareExchange(exchangeName string) error {
 	if err := c.channel.ExchangeDeclare(
 		exchangeName,
@@ -93,9 +119,7 @@ func (c *Consumer) declareExchange(exchangeName string) error {
 		return err
 	}
 
-	c.mu.Lock()
 	c.exchanges[exchangeName] = true
-	c.mu.Unlock()
 
 	return nil
 }
@@ -103,8 +127,8 @@ func (c *Consumer) declareExchange(exchangeName string) error {
 // Subscribe sets up a queue and binds it to an exchange with the specified routing key
 // This method handles both queue declaration and queue binding operations
 func (c *Consumer) Subscribe(exchange, routingKey, queueName string) error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	if !c.isConnected {
 		return fmt.Errorf("consumer is not connected")
@@ -142,9 +166,7 @@ func (c *Consumer) Subscribe(exchange, routingKey, queueName string) error {
 	}
 
 	// Track the exchange
-	c.mu.Lock()
 	c.exchanges[exchange] = true
-	c.mu.Unlock()
 
 	return nil
 }
@@ -237,13 +259,13 @@ func (c *Consumer) handleReconnection() error {
 // startConsuming handles the actual message consumption
 func (c *Consumer) startConsuming(outputChan chan entity.Event) error {
 	msgs, err := c.channel.Consume(
-		c.cfg.Queues["request"], // queue to consume from
-		"",                      // consumer identifier
-		true,                    // auto-acknowledge messages
-		false,                   // exclusive consumer
-		false,                   // no-local flag
-		false,                   // no-wait flag
-		nil,                     // arguments
+		c.queue, // queue to consume from
+		"",      // consumer identifier
+		true,    // auto-acknowledge messages
+		false,   // exclusive consumer
+		false,   // no-local flag
+		false,   // no-wait flag
+		nil,     // arguments
 	)
 	if err != nil {
 		return fmt.Errorf("failed to register consumer: %w", err)
@@ -264,14 +286,18 @@ func (c *Consumer) startConsuming(outputChan chan entity.Event) error {
 
 // processMessage handles individual message processing
 func (c *Consumer) processMessage(msg amqp.Delivery, outputChan chan entity.Event) error {
-	event := new(entity.Event)
-	if err := json.Unmarshal(msg.Body, event); err != nil {
+	event, err := decodeMessage(msg)
+	if err != nil {
 		c.logger.Error("failed to unmarshal event",
 			zap.Error(err),
 			zap.ByteString("body", msg.Body))
 		return fmt.Errorf("failed to unmarshal message: %w", err)
 	}
 
+	// Kept so the listener can answer RPC-style requests
+	event.ReplyTo = msg.ReplyTo
+	event.CorrelationID = msg.CorrelationId
+
 	c.logger.Debug("received new event",
 		zap.String("event_id", event.ID),
 		zap.String("routing_key", event.Type),
@@ -299,7 +325,7 @@ func (c *Consumer) rebindExchanges() error {
 
 	for _, exchange := range exchanges {
 		if err := c.channel.QueueBind(
-			c.cfg.Queues["request"],
+			c.queue,
 			EXCHANGE_TYPE,
 			exchange,
 			false,
@@ -317,6 +343,7 @@ func (c *Consumer) rebindExchanges() error {
 
 // reconnect handles the reconnection logic when the RabbitMQ connection is lost
 // It re-establishes the connection, recreates the channel, and redeclares all exchanges
+// The caller must hold the write lock
 func (c *Consumer) reconnect() error {
 	c.cleanup()
 
@@ -334,13 +361,11 @@ func (c *Consumer) reconnect() error {
 		return err
 	}
 
-	// Redeclare all exchanges
-	c.mu.RLock()
+	// Redeclare all exchanges; handleReconnection holds the write lock
 	exchanges := make([]string, 0, len(c.exchanges))
 	for exchange := range c.exchanges {
 		exchanges = append(exchanges, exchange)
 	}
-	c.mu.RUnlock()
 
 	for _, exchange := range exchanges {
 		if err := c.declareExchange(exchange); err != nil {
@@ -368,3 +393,14 @@ func (c *Consumer) cleanup() {
 		c.conn = nil
 	}
 }
+
+// decodeMessage picks the decoder by the message content type:
+// application/x-protobuf bodies are protobuf events, anything else is JSON
+// in one of the CloudEvents modes or the legacy envelope
+func decodeMessage(msg amqp.Delivery) (*entity.Event, error) {
+	if envelope.IsProtobuf(msg.ContentType) {
+		return envelope.UnmarshalProtobuf(msg.Body)
+	}
+
+	return envelope.FromAMQP(msg.Body, msg.ContentType, msg.Headers)
+}
@@ -0,0 +1,75 @@
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeChannel records queue bindings instead of talking to RabbitMQ
+type fakeChannel struct {
+	bindings []string
+}
+
+func (f *fakeChannel) ExchangeDeclare(string, string, bool, bool, bool, bool, amqp.Table) error {
+	return nil
+}
+
+func (f *fakeChannel) QueueDeclare(name string, _, _, _, _ bool, _ amqp.Table) (amqp.Queue, error) {
+	return amqp.Queue{Name: name}, nil
+}
+
+func (f *fakeChannel) QueueBind(name, key, exchange string, _ bool, _ amqp.Table) error {
+	f.bindings = append(f.bindings, exchange+"/"+key+"->"+name)
+	return nil
+}
+
+func (f *fakeChannel) Consume(string, string, bool, bool, bool, bool, amqp.Table) (<-chan amqp.Delivery, error) {
+	return nil, nil
+}
+
+func (f *fakeChannel) Close() error {
+	return nil
+}
+
+func TestSubscribeMoreThanOnce(t *testing.T) {
+	channel := new(fakeChannel)
+
+	c := &Consumer{
+		channel:     channel,
+		logger:      logger.Get(),
+		queue:       "form",
+		exchanges:   make(map[string]bool),
+		isConnected: true,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for _, key := range []string{"form.created", "form.updated", "form.deleted"} {
+			if err := c.Subscribe("form", key, "form"); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe deadlocked")
+	}
+
+	if len(channel.bindings) != 3 {
+		t.Fatalf("got bindings %v, want 3", channel.bindings)
+	}
+
+	if !c.exchanges["form"] {
+		t.Fatal("exchange form is not tracked")
+	}
+}
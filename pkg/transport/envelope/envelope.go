@@ -0,0 +1,282 @@
+// Package envelope converts entity.Event to and from the wire formats:
+// CloudEvents 1.0 in structured or binary content mode, and the legacy
+// {id, payload, type, timestamp} envelope, which is still accepted on input
+package envelope
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+)
+
+const (
+	SpecVersion = "1.0"
+
+	ContentTypeJSON            = "application/json"
+	ContentTypeCloudEventsJSON = "application/cloudevents+json"
+
+	// DefaultSource identifies this service as the producer of events
+	DefaultSource = "/answer-service"
+
+	// AMQPHeaderPrefix prefixes CloudEvents attributes in AMQP headers (binary mode)
+	AMQPHeaderPrefix = "cloudEvents:"
+)
+
+// Content modes for published events
+const (
+	ModeStructured = "structured"
+	ModeBinary     = "binary"
+	ModeLegacy     = "legacy"
+)
+
+var ErrMissingAttribute = errors.New("missing required cloudevents attribute")
+
+// structured is the CloudEvents JSON event format
+// The payload schema version travels as the "schemaversion" extension attribute
+type structured struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            *time.Time      `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	SchemaVersion   int             `json:"schemaversion,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      []byte          `json:"data_base64,omitempty"`
+}
+
+// New creates an event with JSON data, filling the CloudEvents attributes
+// The subject is the "id" field of data (the answer ID) when it has one
+func New(source, eventType string, data []byte) *entity.Event {
+	event := entity.NewEvent(eventType, data)
+	event.Source = source
+	event.Subject = SubjectOf(data)
+	event.DataContentType = ContentTypeJSON
+
+	return event
+}
+
+// SubjectOf returns the "id" field of a JSON payload, or "" if there is none
+func SubjectOf(data []byte) string {
+	ids := struct {
+		ID string `json:"id"`
+	}{}
+
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return ""
+	}
+
+	return ids.ID
+}
+
+// Marshal encodes event as a single message body in structured or legacy mode
+// Binary mode needs per-message attributes (see ToAMQP), so transports
+// without them fall back to structured mode
+// Returns the body and the content type to send it with
+func Marshal(event *entity.Event, mode string) ([]byte, string, error) {
+	switch mode {
+	case ModeLegacy:
+		body, err := json.Marshal(event)
+		return body, ContentTypeJSON, err
+	case "", ModeStructured, ModeBinary:
+		body, err := json.Marshal(toStructured(event))
+		return body, ContentTypeCloudEventsJSON, err
+	default:
+		return nil, "", fmt.Errorf("unknown event content mode: %s", mode)
+	}
+}
+
+// Unmarshal decodes a message body holding either a structured CloudEvent
+// or a legacy envelope
+func Unmarshal(body []byte) (*entity.Event, error) {
+	probe := struct {
+		SpecVersion string `json:"specversion"`
+	}{}
+
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	if probe.SpecVersion == "" {
+		event := new(entity.Event)
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal legacy event: %w", err)
+		}
+		return event, nil
+	}
+
+	ce := new(structured)
+	if err := json.Unmarshal(body, ce); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cloudevent: %w", err)
+	}
+
+	return fromStructured(ce)
+}
+
+// ToAMQP encodes event for an AMQP message in the given mode
+// In binary mode the attributes travel as "cloudEvents:" headers and the
+// body is the bare data; otherwise headers is nil
+func ToAMQP(event *entity.Event, mode string) (body []byte, contentType string, headers map[string]any, err error) {
+	if mode != ModeBinary {
+		body, contentType, err = Marshal(event, mode)
+		return body, contentType, nil, err
+	}
+
+	headers = map[string]any{
+		AMQPHeaderPrefix + "specversion": SpecVersion,
+		AMQPHeaderPrefix + "id":          event.ID,
+		AMQPHeaderPrefix + "source":      sourceOf(event),
+		AMQPHeaderPrefix + "type":        event.Type,
+		AMQPHeaderPrefix + "time":        event.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	if event.Subject != "" {
+		headers[AMQPHeaderPrefix+"subject"] = event.Subject
+	}
+	if event.SchemaVersion > 0 {
+		headers[AMQPHeaderPrefix+"schemaversion"] = strconv.Itoa(event.SchemaVersion)
+	}
+
+	contentType = event.DataContentType
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	return event.Payload, contentType, headers, nil
+}
+
+// FromAMQP decodes an AMQP message in any supported mode: binary when the
+// headers carry cloudEvents:specversion, structured or legacy otherwise
+func FromAMQP(body []byte, contentType string, headers map[string]any) (*entity.Event, error) {
+	if _, ok := headers[AMQPHeaderPrefix+"specversion"]; !ok {
+		return Unmarshal(body)
+	}
+
+	attr := func(name string) string {
+		value, _ := headers[AMQPHeaderPrefix+name].(string)
+		return value
+	}
+
+	event := &entity.Event{
+		ID:              attr("id"),
+		Source:          attr("source"),
+		Type:            attr("type"),
+		Subject:         attr("subject"),
+		DataContentType: contentType,
+		Payload:         body,
+	}
+
+	if raw := attr("time"); raw != "" {
+		ts, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cloudevents time %q: %w", raw, err)
+		}
+		event.Timestamp = ts
+	}
+
+	if raw := attr("schemaversion"); raw != "" {
+		version, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cloudevents schemaversion %q: %w", raw, err)
+		}
+		event.SchemaVersion = version
+	}
+
+	if err := requireAttributes(event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func toStructured(event *entity.Event) *structured {
+	ce := &structured{
+		SpecVersion:     SpecVersion,
+		ID:              event.ID,
+		Source:          sourceOf(event),
+		Type:            event.Type,
+		Subject:         event.Subject,
+		DataContentType: event.DataContentType,
+		SchemaVersion:   event.SchemaVersion,
+	}
+
+	if !event.Timestamp.IsZero() {
+		ts := event.Timestamp
+		ce.Time = &ts
+	}
+
+	if isJSON(event.DataContentType) && json.Valid(event.Payload) {
+		ce.Data = event.Payload
+		if ce.DataContentType == "" {
+			ce.DataContentType = ContentTypeJSON
+		}
+	} else {
+		ce.DataBase64 = event.Payload
+	}
+
+	return ce
+}
+
+func fromStructured(ce *structured) (*entity.Event, error) {
+	event := &entity.Event{
+		ID:              ce.ID,
+		Source:          ce.Source,
+		Type:            ce.Type,
+		Subject:         ce.Subject,
+		DataContentType: ce.DataContentType,
+		SchemaVersion:   ce.SchemaVersion,
+		Payload:         ce.Data,
+	}
+
+	if ce.DataBase64 != nil {
+		event.Payload = ce.DataBase64
+	}
+
+	if ce.Time != nil {
+		event.Timestamp = *ce.Time
+	}
+
+	if err := requireAttributes(event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func requireAttributes(event *entity.Event) error {
+	switch {
+	case event.ID == "":
+		return fmt.Errorf("%w: id", ErrMissingAttribute)
+	case event.Source == "":
+		return fmt.Errorf("%w: source", ErrMissingAttribute)
+	case event.Type == "":
+		return fmt.Errorf("%w: type", ErrMissingAttribute)
+	}
+
+	return nil
+}
+
+func sourceOf(event *entity.Event) string {
+	if event.Source != "" {
+		return event.Source
+	}
+	return DefaultSource
+}
+
+// isJSON reports whether data of this content type is embedded as JSON
+// An absent content type means JSON, as the spec defines for the JSON format
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+
+	return mediaType == ContentTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
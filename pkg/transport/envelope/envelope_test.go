@@ -0,0 +1,126 @@
+package envelope_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/pkg/transport/envelope"
+)
+
+func newEvent(contentType string, payload []byte) *entity.Event {
+	event := envelope.New(envelope.DefaultSource, "answer.created", payload)
+	event.DataContentType = contentType
+	event.SchemaVersion = 2
+
+	return event
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		contentType string
+		payload     []byte
+		wantType    string
+		headers     bool
+	}{
+		{"structured", envelope.ModeStructured, envelope.ContentTypeJSON, []byte(`{"id":"a1"}`), envelope.ContentTypeCloudEventsJSON, false},
+		{"structured binary data", envelope.ModeStructured, "application/octet-stream", []byte{0, 1, 2}, envelope.ContentTypeCloudEventsJSON, false},
+		{"binary", envelope.ModeBinary, envelope.ContentTypeJSON, []byte(`{"id":"a1"}`), envelope.ContentTypeJSON, true},
+		{"binary non-JSON", envelope.ModeBinary, "application/octet-stream", []byte{0, 1, 2}, "application/octet-stream", true},
+		{"legacy", envelope.ModeLegacy, envelope.ContentTypeJSON, []byte(`{"id":"a1"}`), envelope.ContentTypeJSON, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := newEvent(tt.contentType, tt.payload)
+
+			body, contentType, headers, err := envelope.ToAMQP(event, tt.mode)
+			if err != nil {
+				t.Fatalf("ToAMQP: %v", err)
+			}
+			if contentType != tt.wantType {
+				t.Fatalf("content type = %q, want %q", contentType, tt.wantType)
+			}
+			if (headers != nil) != tt.headers {
+				t.Fatalf("headers = %v, want headers %v", headers, tt.headers)
+			}
+
+			got, err := envelope.FromAMQP(body, contentType, headers)
+			if err != nil {
+				t.Fatalf("FromAMQP: %v", err)
+			}
+
+			if got.ID != event.ID || got.Type != event.Type || got.Source != event.Source || got.Subject != event.Subject {
+				t.Fatalf("event = %+v, want %+v", got, event)
+			}
+			if got.SchemaVersion != event.SchemaVersion {
+				t.Fatalf("SchemaVersion = %d, want %d", got.SchemaVersion, event.SchemaVersion)
+			}
+			if !got.Timestamp.Equal(event.Timestamp) {
+				t.Fatalf("Timestamp = %v, want %v", got.Timestamp, event.Timestamp)
+			}
+			if !bytes.Equal(got.Payload, event.Payload) {
+				t.Fatalf("Payload = %q, want %q", got.Payload, event.Payload)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantID  string
+		source  string
+		payload string
+		wantErr bool
+		err     error
+	}{
+		{
+			name:    "structured",
+			body:    `{"specversion":"1.0","id":"e1","source":"/forms","type":"form.created","data":{"id":"f1"}}`,
+			wantID:  "e1",
+			source:  "/forms",
+			payload: `{"id":"f1"}`,
+		},
+		{
+			name:    "legacy",
+			body:    `{"id":"e2","type":"form.created","payload":"eyJpZCI6ImYxIn0="}`,
+			wantID:  "e2",
+			payload: `{"id":"f1"}`,
+		},
+		{
+			name:    "structured without source",
+			body:    `{"specversion":"1.0","id":"e3","type":"form.created"}`,
+			wantErr: true,
+			err:     envelope.ErrMissingAttribute,
+		},
+		{
+			name:    "not JSON",
+			body:    `<event/>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := envelope.Unmarshal([]byte(tt.body))
+			if tt.wantErr {
+				if err == nil || tt.err != nil && !errors.Is(err, tt.err) {
+					t.Fatalf("Unmarshal = %v, want an error %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+
+			if event.ID != tt.wantID || event.Source != tt.source || string(event.Payload) != tt.payload {
+				t.Fatalf("event = %+v (payload %s), want id %s from %q with %s", event, event.Payload, tt.wantID, tt.source, tt.payload)
+			}
+		})
+	}
+}
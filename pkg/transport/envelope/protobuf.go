@@ -0,0 +1,247 @@
+package envelope
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/pkg/pb/answerv1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const ContentTypeProtobuf = "application/x-protobuf"
+
+// Encodings of published events
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+)
+
+type payloadKind int
+
+const (
+	payloadJSON payloadKind = iota
+	payloadAnswer
+	payloadAnswerRef
+)
+
+// protoPayloads lists the event types whose payload has a protobuf message;
+// payloads of other types stay JSON inside the protobuf envelope
+var protoPayloads = map[string]payloadKind{
+	"request.answer.create": payloadAnswer,
+	"request.answer.delete": payloadAnswerRef,
+	"request.answer.get":    payloadAnswerRef,
+	"answer.created":        payloadAnswer,
+	"answer.updated":        payloadAnswer,
+	"answer.completed":      payloadAnswer,
+	"answer.deleted":        payloadAnswerRef,
+}
+
+type (
+	// answerJSON mirrors the JSON shape of entity.Answer, leaving out unset
+	// fields so schema validation still reports them as missing
+	answerJSON struct {
+		ID         string        `json:"id,omitempty"`
+		FormID     string        `json:"form_id,omitempty"`
+		UserID     string        `json:"user_id,omitempty"`
+		IsComplete bool          `json:"is_complete"`
+		Elements   []elementJSON `json:"elements,omitempty"`
+		CreatedAt  *time.Time    `json:"created_at,omitempty"`
+		UpdatedAt  *time.Time    `json:"updated_at,omitempty"`
+		Score      *float64      `json:"score,omitempty"`
+		MaxScore   *float64      `json:"max_score,omitempty"`
+	}
+
+	elementJSON struct {
+		QuestionOrderNumber uint32   `json:"question_order_number"`
+		Content             string   `json:"content"`
+		ValueType           string   `json:"value_type"`
+		Correct             *bool    `json:"correct,omitempty"`
+		Points              *float64 `json:"points,omitempty"`
+	}
+)
+
+// IsProtobuf reports whether contentType is the protobuf content type
+func IsProtobuf(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.TrimSpace(mediaType) == ContentTypeProtobuf
+}
+
+// MarshalProtobuf encodes event as an answer.v1.Event
+// JSON payloads of types listed in protoPayloads are converted to their
+// protobuf message; other payloads are embedded as they are
+func MarshalProtobuf(event *entity.Event) ([]byte, error) {
+	payload, contentType, err := payloadToProto(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", event.Type, err)
+	}
+
+	msg := &answerv1.Event{
+		Id:              event.ID,
+		Type:            event.Type,
+		SchemaVersion:   int32(event.SchemaVersion),
+		Source:          sourceOf(event),
+		Subject:         event.Subject,
+		DataContentType: contentType,
+		Payload:         payload,
+	}
+	if !event.Timestamp.IsZero() {
+		msg.Timestamp = timestamppb.New(event.Timestamp)
+	}
+
+	return proto.Marshal(msg)
+}
+
+// UnmarshalProtobuf decodes an answer.v1.Event
+// Protobuf payloads are converted back to JSON, so handlers and schema
+// validation see the same payload whichever encoding the producer chose
+func UnmarshalProtobuf(body []byte) (*entity.Event, error) {
+	msg := new(answerv1.Event)
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal protobuf event: %w", err)
+	}
+
+	event := &entity.Event{
+		ID:              msg.GetId(),
+		Type:            msg.GetType(),
+		SchemaVersion:   int(msg.GetSchemaVersion()),
+		Source:          msg.GetSource(),
+		Subject:         msg.GetSubject(),
+		DataContentType: msg.GetDataContentType(),
+		Payload:         msg.GetPayload(),
+	}
+	if msg.GetTimestamp() != nil {
+		event.Timestamp = msg.GetTimestamp().AsTime()
+	}
+
+	if IsProtobuf(event.DataContentType) {
+		payload, err := payloadFromProto(event.Type, event.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s payload: %w", event.Type, err)
+		}
+		event.Payload = payload
+		event.DataContentType = ContentTypeJSON
+	}
+
+	if err := requireAttributes(event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func payloadToProto(event *entity.Event) ([]byte, string, error) {
+	kind := protoPayloads[event.Type]
+	if kind == payloadJSON || !isJSON(event.DataContentType) {
+		return event.Payload, event.DataContentType, nil
+	}
+
+	var msg proto.Message
+
+	switch kind {
+	case payloadAnswer:
+		answer := new(answerJSON)
+		if err := json.Unmarshal(event.Payload, answer); err != nil {
+			return nil, "", err
+		}
+		msg = answerToProto(answer)
+	case payloadAnswerRef:
+		ref := new(answerJSON)
+		if err := json.Unmarshal(event.Payload, ref); err != nil {
+			return nil, "", err
+		}
+		msg = &answerv1.AnswerRef{Id: ref.ID}
+	}
+
+	payload, err := proto.Marshal(msg)
+	return payload, ContentTypeProtobuf, err
+}
+
+func payloadFromProto(eventType string, payload []byte) ([]byte, error) {
+	switch protoPayloads[eventType] {
+	case payloadAnswer:
+		msg := new(answerv1.Answer)
+		if err := proto.Unmarshal(payload, msg); err != nil {
+			return nil, err
+		}
+		return json.Marshal(answerFromProto(msg))
+	case payloadAnswerRef:
+		msg := new(answerv1.AnswerRef)
+		if err := proto.Unmarshal(payload, msg); err != nil {
+			return nil, err
+		}
+		return json.Marshal(&answerJSON{ID: msg.GetId()})
+	default:
+		return nil, fmt.Errorf("event type has no protobuf payload")
+	}
+}
+
+func answerToProto(answer *answerJSON) *answerv1.Answer {
+	msg := &answerv1.Answer{
+		Id:         answer.ID,
+		FormId:     answer.FormID,
+		UserId:     answer.UserID,
+		IsComplete: answer.IsComplete,
+		Score:      answer.Score,
+		MaxScore:   answer.MaxScore,
+	}
+
+	for _, element := range answer.Elements {
+		msg.Elements = append(msg.Elements, &answerv1.Element{
+			QuestionOrderNumber: element.QuestionOrderNumber,
+			Content:             element.Content,
+			ValueType:           element.ValueType,
+			Correct:             element.Correct,
+			Points:              element.Points,
+		})
+	}
+
+	if answer.CreatedAt != nil && !answer.CreatedAt.IsZero() {
+		msg.CreatedAt = timestamppb.New(*answer.CreatedAt)
+	}
+	if answer.UpdatedAt != nil && !answer.UpdatedAt.IsZero() {
+		msg.UpdatedAt = timestamppb.New(*answer.UpdatedAt)
+	}
+
+	return msg
+}
+
+func answerFromProto(msg *answerv1.Answer) *answerJSON {
+	answer := &answerJSON{
+		ID:         msg.GetId(),
+		FormID:     msg.GetFormId(),
+		UserID:     msg.GetUserId(),
+		IsComplete: msg.GetIsComplete(),
+		Score:      msg.Score,
+		MaxScore:   msg.MaxScore,
+	}
+
+	for _, element := range msg.GetElements() {
+		valueType := element.GetValueType()
+		if valueType == "" {
+			valueType = entity.ValueTypeString
+		}
+
+		answer.Elements = append(answer.Elements, elementJSON{
+			QuestionOrderNumber: element.GetQuestionOrderNumber(),
+			Content:             element.GetContent(),
+			ValueType:           valueType,
+			Correct:             element.Correct,
+			Points:              element.Points,
+		})
+	}
+
+	if msg.GetCreatedAt() != nil {
+		ts := msg.GetCreatedAt().AsTime()
+		answer.CreatedAt = &ts
+	}
+	if msg.GetUpdatedAt() != nil {
+		ts := msg.GetUpdatedAt().AsTime()
+		answer.UpdatedAt = &ts
+	}
+
+	return answer
+}
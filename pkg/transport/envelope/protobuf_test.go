@@ -0,0 +1,79 @@
+package envelope_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Koyo-os/answer-service/pkg/transport/envelope"
+)
+
+func TestProtobufRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+	}{
+		{
+			name:      "scored answer",
+			eventType: "answer.created",
+			payload: `{"id":"a1","form_id":"f1","user_id":"u1","is_complete":true,"score":2,"max_score":3,` +
+				`"created_at":"2024-05-01T10:00:00Z","updated_at":"2024-05-01T10:05:00Z",` +
+				`"elements":[{"question_order_number":1,"content":"4","value_type":"number","correct":true,"points":2},` +
+				`{"question_order_number":2,"content":"b","value_type":"string","correct":false,"points":0}]}`,
+		},
+		{
+			name:      "answer request",
+			eventType: "request.answer.create",
+			payload:   `{"form_id":"f1","user_id":"u1","is_complete":false,"elements":[{"question_order_number":1,"content":"hi","value_type":"string"}]}`,
+		},
+		{
+			name:      "answer reference",
+			eventType: "request.answer.delete",
+			payload:   `{"id":"a1","is_complete":false}`,
+		},
+		{
+			name:      "JSON payload",
+			eventType: "form.created",
+			payload:   `{"id":"f1","questions":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := envelope.New(envelope.DefaultSource, tt.eventType, []byte(tt.payload))
+			event.SchemaVersion = 2
+
+			body, err := envelope.MarshalProtobuf(event)
+			if err != nil {
+				t.Fatalf("MarshalProtobuf: %v", err)
+			}
+
+			got, err := envelope.UnmarshalProtobuf(body)
+			if err != nil {
+				t.Fatalf("UnmarshalProtobuf: %v", err)
+			}
+
+			if got.ID != event.ID || got.Type != event.Type || got.Source != event.Source || got.Subject != event.Subject {
+				t.Fatalf("event = %+v, want %+v", got, event)
+			}
+			if got.SchemaVersion != event.SchemaVersion || !got.Timestamp.Equal(event.Timestamp) {
+				t.Fatalf("version %d at %v, want %d at %v", got.SchemaVersion, got.Timestamp, event.SchemaVersion, event.Timestamp)
+			}
+			if got.DataContentType != envelope.ContentTypeJSON {
+				t.Fatalf("DataContentType = %q, want %q", got.DataContentType, envelope.ContentTypeJSON)
+			}
+
+			var want, have any
+			if err := json.Unmarshal([]byte(tt.payload), &want); err != nil {
+				t.Fatalf("test payload: %v", err)
+			}
+			if err := json.Unmarshal(got.Payload, &have); err != nil {
+				t.Fatalf("decoded payload %s: %v", got.Payload, err)
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Fatalf("payload = %s, want %s", got.Payload, tt.payload)
+			}
+		})
+	}
+}
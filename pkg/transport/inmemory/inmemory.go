@@ -0,0 +1,209 @@
+// Package inmemory provides a channel-based event broker that runs inside
+// the process, so the whole pipeline can run without RabbitMQ
+package inmemory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/envelope"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultBufferSize is the capacity of each subscription channel
+	DefaultBufferSize = 100
+
+	// RequestPrefix matches the request events the service consumes
+	RequestPrefix = "request."
+
+	// FormPrefix matches the form service events kept in the form replica
+	FormPrefix = "form."
+)
+
+var ErrBrokerClosed = errors.New("broker is closed")
+
+type (
+	// Broker fans out events to every subscription whose prefix matches the event type
+	Broker struct {
+		mu         sync.RWMutex
+		subs       []chan entity.Event
+		prefixes   []string
+		bufferSize int
+		closed     bool
+	}
+
+	// Publisher sends events to a Broker
+	Publisher struct {
+		broker *Broker
+		logger *logger.Logger
+	}
+
+	// Consumer forwards the events of one Broker subscription
+	Consumer struct {
+		broker *Broker
+		events <-chan entity.Event
+		logger *logger.Logger
+	}
+)
+
+// NewBroker creates a broker whose subscriptions buffer up to bufferSize events
+func NewBroker(bufferSize int) *Broker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	return &Broker{
+		bufferSize: bufferSize,
+	}
+}
+
+// Subscribe returns a channel receiving every event whose type starts with prefix
+// An empty prefix receives everything. The channel is closed with the broker.
+func (b *Broker) Subscribe(prefix string) <-chan entity.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan entity.Event, b.bufferSize)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+
+	b.subs = append(b.subs, ch)
+	b.prefixes = append(b.prefixes, prefix)
+
+	return ch
+}
+
+// Send delivers event to the matching subscriptions
+// It fails instead of blocking when a subscription buffer is full
+func (b *Broker) Send(event entity.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	for i, ch := range b.subs {
+		if !strings.HasPrefix(event.Type, b.prefixes[i]) {
+			continue
+		}
+
+		select {
+		case ch <- event:
+		default:
+			return fmt.Errorf("subscription %q is full, dropping event: %s", b.prefixes[i], event.ID)
+		}
+	}
+
+	return nil
+}
+
+// Close closes every subscription channel
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+	for _, ch := range b.subs {
+		close(ch)
+	}
+
+	return nil
+}
+
+func (b *Broker) isClosed() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.closed
+}
+
+func NewPublisher(broker *Broker, logger *logger.Logger) *Publisher {
+	return &Publisher{
+		broker: broker,
+		logger: logger,
+	}
+}
+
+// Publish encodes payload as JSON and sends it as an event of type routingKey
+// with the same CloudEvents attributes the broker transports set
+func (p *Publisher) Publish(payload any, routingKey string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		p.logger.Error("error encode payload for publish", zap.Error(err))
+		return err
+	}
+
+	event := envelope.New(envelope.DefaultSource, routingKey, data)
+
+	if err := p.broker.Send(*event); err != nil {
+		p.logger.Error("error publishing event",
+			zap.String("event_id", event.ID),
+			zap.Error(err))
+		return err
+	}
+
+	p.logger.Debug("successfully published event",
+		zap.String("event_id", event.ID),
+		zap.String("event_type", event.Type))
+
+	return nil
+}
+
+// Close is a no-op; the broker is closed by its owner
+func (p *Publisher) Close() error {
+	return nil
+}
+
+func (p *Publisher) IsHealthy() bool {
+	return !p.broker.isClosed()
+}
+
+// NewConsumer subscribes to events whose type starts with prefix
+// The subscription starts immediately so no events are missed before
+// ConsumeMessages is called
+func NewConsumer(broker *Broker, logger *logger.Logger, prefix string) *Consumer {
+	return &Consumer{
+		broker: broker,
+		events: broker.Subscribe(prefix),
+		logger: logger,
+	}
+}
+
+// ConsumeMessages forwards subscribed events to outputChan until the broker is closed
+func (c *Consumer) ConsumeMessages(outputChan chan entity.Event) {
+	if outputChan == nil {
+		c.logger.Error("output channel cannot be nil")
+		return
+	}
+
+	for event := range c.events {
+		c.logger.Debug("received new event",
+			zap.String("event_id", event.ID),
+			zap.String("routing_key", event.Type),
+			zap.Time("timestamp", event.Timestamp))
+
+		outputChan <- event
+	}
+}
+
+// Close is a no-op; the broker is closed by its owner
+func (c *Consumer) Close() error {
+	return nil
+}
+
+func (c *Consumer) IsHealthy() bool {
+	return !c.broker.isClosed()
+}
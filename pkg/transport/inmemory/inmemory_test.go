@@ -0,0 +1,77 @@
+package inmemory_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/repository"
+	"github.com/Koyo-os/answer-service/internal/service"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/casher"
+	"github.com/Koyo-os/answer-service/pkg/transport/inmemory"
+	"github.com/Koyo-os/answer-service/pkg/transport/listener"
+	"github.com/google/uuid"
+)
+
+// TestPipeline runs a request through the broker, listener and service
+// the way main wires the inmemory transport
+func TestPipeline(t *testing.T) {
+	log := logger.Get()
+
+	broker := inmemory.NewBroker(0)
+	t.Cleanup(func() { broker.Close() })
+
+	published := broker.Subscribe(service.AnswerCreatedEventType)
+	publisher := inmemory.NewPublisher(broker, log)
+	consumer := inmemory.NewConsumer(broker, log, inmemory.RequestPrefix)
+
+	repo := repository.NewMemoryRepository()
+	svc := service.NewService(casher.NewMemory(), publisher, repo, repo, 5*time.Second)
+
+	events := make(chan entity.Event, inmemory.DefaultBufferSize)
+	l := listener.NewListener(log, svc, events)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go consumer.ConsumeMessages(events)
+	go l.Run(ctx)
+
+	request := &entity.Answer{
+		ID:         uuid.New(),
+		FormID:     uuid.New(),
+		UserID:     uuid.New(),
+		IsComplete: true,
+	}
+	request.AddElement(1, "hello")
+
+	if err := publisher.Publish(request, listener.EventTypeAnswerCreate); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	var event entity.Event
+	select {
+	case event = <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no %s event was published", service.AnswerCreatedEventType)
+	}
+
+	created := new(entity.Answer)
+	if err := json.Unmarshal(event.Payload, created); err != nil {
+		t.Fatalf("decode %s payload: %v", event.Type, err)
+	}
+	if created.ID != request.ID {
+		t.Fatalf("%s for answer %s, want %s", event.Type, created.ID, request.ID)
+	}
+
+	stored, err := repo.GetAnswer(ctx, request.ID)
+	if err != nil {
+		t.Fatalf("stored answer: %v", err)
+	}
+	if stored.FormID != request.FormID || len(stored.Elements) != 1 || stored.Elements[0].Content != "hello" {
+		t.Fatalf("stored answer = %+v, want the published request", stored)
+	}
+}
@@ -0,0 +1,329 @@
+// Package jetstream provides a NATS JetStream implementation of the event
+// transport. Request events arrive on request.answer.* subjects and form
+// service events on form.* subjects through durable consumers with explicit
+// acknowledgement; result events are published on answer.* subjects named
+// after the event type.
+package jetstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/envelope"
+	"github.com/nats-io/nats.go"
+	js "github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+const (
+	DEFAULT_RETRY_DELAY     = 5 * time.Second
+	DEFAULT_PUBLISH_TIMEOUT = 5 * time.Second
+
+	// DEFAULT_ACK_WAIT is JetStream's own AckWait, used when the config sets none
+	DEFAULT_ACK_WAIT = 30 * time.Second
+)
+
+type (
+	// Publisher publishes events to the output stream
+	Publisher struct {
+		conn   *nats.Conn
+		js     js.JetStream
+		logger *logger.Logger
+		events config.Events
+	}
+
+	// Consumer reads events through a durable JetStream consumer
+	Consumer struct {
+		conn     *nats.Conn
+		consumer js.Consumer
+		logger   *logger.Logger
+		ctx      context.Context
+		cancel   context.CancelFunc
+		closed   atomic.Bool
+		progress time.Duration // Interval of InProgress heartbeats while an event is handled
+	}
+)
+
+// Connect dials NATS and makes sure the request, form and output streams exist
+func Connect(cfg *config.Config) (*nats.Conn, error) {
+	ncfg := cfg.Transport.NATS
+
+	conn, err := nats.Connect(ncfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	stream, err := js.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_PUBLISH_TIMEOUT)
+	defer cancel()
+
+	for name, subjects := range map[string]string{
+		ncfg.RequestStream: ncfg.RequestSubjects,
+		ncfg.FormStream:    ncfg.FormSubjects,
+		ncfg.OutputStream:  ncfg.OutputSubjects,
+	} {
+		if _, err := stream.CreateOrUpdateStream(ctx, js.StreamConfig{
+			Name:     name,
+			Subjects: []string{subjects},
+			Storage:  js.FileStorage,
+		}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to declare stream %s: %w", name, err)
+		}
+	}
+
+	return conn, nil
+}
+
+func NewPublisher(cfg *config.Config, logger *logger.Logger, conn *nats.Conn) (*Publisher, error) {
+	stream, err := js.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+
+	return &Publisher{
+		conn:   conn,
+		js:     stream,
+		logger: logger,
+		events: cfg.Events,
+	}, nil
+}
+
+// Publish encodes payload into an event and publishes it on the subject
+// named after routingKey (e.g. answer.created). The event ID is used as
+// the JetStream message ID, so retried publishes are deduplicated.
+func (p *Publisher) Publish(payload any, routingKey string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		p.logger.Error("error encode payload for publish", zap.Error(err))
+		return err
+	}
+
+	event := envelope.New(p.events.Source, routingKey, data)
+
+	body, contentType, err := envelope.Marshal(event, p.events.Mode)
+	if err != nil {
+		p.logger.Error("error encode event for publish",
+			zap.String("event_id", event.ID),
+			zap.Error(err))
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_PUBLISH_TIMEOUT)
+	defer cancel()
+
+	msg := nats.NewMsg(routingKey)
+	msg.Data = body
+	msg.Header.Set("Content-Type", contentType)
+
+	if _, err := p.js.PublishMsg(ctx, msg, js.WithMsgID(event.ID)); err != nil {
+		p.logger.Error("error publishing event",
+			zap.String("event_id", event.ID),
+			zap.String("subject", routingKey),
+			zap.Error(err))
+		return err
+	}
+
+	p.logger.Info("successfully published event",
+		zap.String("event_id", event.ID))
+
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return p.conn.Drain()
+}
+
+func (p *Publisher) IsHealthy() bool {
+	return p.conn.IsConnected()
+}
+
+// NewConsumer creates (or updates) the durable consumer on the request stream
+func NewConsumer(cfg *config.Config, logger *logger.Logger, conn *nats.Conn) (*Consumer, error) {
+	ncfg := cfg.Transport.NATS
+	return NewStreamConsumer(cfg, logger, conn, ncfg.RequestStream, ncfg.RequestSubjects, ncfg.Durable)
+}
+
+// NewFormConsumer creates (or updates) the durable consumer on the form stream
+func NewFormConsumer(cfg *config.Config, logger *logger.Logger, conn *nats.Conn) (*Consumer, error) {
+	ncfg := cfg.Transport.NATS
+	return NewStreamConsumer(cfg, logger, conn, ncfg.FormStream, ncfg.FormSubjects, ncfg.Durable+"-forms")
+}
+
+// NewStreamConsumer creates (or updates) the durable consumer named durable
+// on streamName, reading the given subjects
+func NewStreamConsumer(cfg *config.Config, logger *logger.Logger, conn *nats.Conn, streamName, subjects, durable string) (*Consumer, error) {
+	ncfg := cfg.Transport.NATS
+
+	stream, err := js.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, streamName, js.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: subjects,
+		AckPolicy:     js.AckExplicitPolicy,
+		AckWait:       ncfg.AckWait,
+		MaxDeliver:    ncfg.MaxDeliver,
+		DeliverPolicy: js.DeliverAllPolicy,
+	})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to declare consumer %s: %w", durable, err)
+	}
+
+	ackWait := ncfg.AckWait
+	if ackWait <= 0 {
+		ackWait = DEFAULT_ACK_WAIT
+	}
+
+	return &Consumer{
+		conn:     conn,
+		consumer: consumer,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+		progress: ackWait / 2,
+	}, nil
+}
+
+// ConsumeMessages pulls messages one at a time until the consumer is closed
+// A message is acknowledged once its event was handled (see processMessage);
+// malformed messages are terminated so they aren't redelivered. Nothing more
+// is pulled meanwhile, so no message waits in the client past AckWait.
+func (c *Consumer) ConsumeMessages(outputChan chan entity.Event) {
+	if outputChan == nil {
+		c.logger.Error("output channel cannot be nil")
+		return
+	}
+
+	for !c.closed.Load() {
+		msgs, err := c.consumer.Messages(js.PullMaxMessages(1))
+		if err != nil {
+			c.logger.Error("failed to start consuming", zap.Error(err))
+			time.Sleep(DEFAULT_RETRY_DELAY)
+			continue
+		}
+
+		c.logger.Info("successfully connected to NATS, waiting for messages...")
+
+		go func() {
+			<-c.ctx.Done()
+			msgs.Stop()
+		}()
+
+		for {
+			msg, err := msgs.Next()
+			if err != nil {
+				if !c.closed.Load() {
+					c.logger.Error("consuming stopped with error", zap.Error(err))
+					time.Sleep(DEFAULT_RETRY_DELAY)
+				}
+				break
+			}
+
+			c.processMessage(msg, outputChan)
+		}
+	}
+}
+
+// processMessage decodes a message, hands it off and waits until the
+// listener settled it. The message is acknowledged once it was handled, or
+// nak'ed for redelivery (at most MaxDeliver times) if that failed; until
+// then InProgress heartbeats keep JetStream from redelivering it.
+func (c *Consumer) processMessage(msg js.Msg, outputChan chan entity.Event) {
+	event, err := envelope.Unmarshal(msg.Data())
+	if err != nil {
+		c.logger.Error("failed to unmarshal event",
+			zap.String("subject", msg.Subject()),
+			zap.Error(err))
+
+		if err := msg.Term(); err != nil {
+			c.logger.Error("failed to terminate message", zap.Error(err))
+		}
+		return
+	}
+
+	if event.Type == "" {
+		event.Type = msg.Subject()
+	}
+
+	c.logger.Debug("received new event",
+		zap.String("event_id", event.ID),
+		zap.String("routing_key", event.Type),
+		zap.Time("timestamp", event.Timestamp))
+
+	settled := make(chan bool, 1)
+	event.Ack = func(requeue bool) {
+		settled <- requeue
+	}
+
+	heartbeat := time.NewTicker(c.progress)
+	defer heartbeat.Stop()
+
+	// out is nil once the event was handed off, which disables its case
+	out := outputChan
+	for {
+		select {
+		case out <- *event:
+			out = nil
+		case requeue := <-settled:
+			c.settle(msg, event.ID, requeue)
+			return
+		case <-heartbeat.C:
+			if err := msg.InProgress(); err != nil {
+				c.logger.Error("failed to extend ack deadline",
+					zap.String("event_id", event.ID),
+					zap.Error(err))
+			}
+		case <-c.ctx.Done():
+			// Let JetStream redeliver it to another instance
+			if out != nil {
+				c.settle(msg, event.ID, true)
+			}
+			return
+		}
+	}
+}
+
+// settle acks a handled message or naks it for redelivery
+func (c *Consumer) settle(msg js.Msg, eventID string, requeue bool) {
+	if requeue {
+		if err := msg.Nak(); err != nil {
+			c.logger.Error("failed to nak message",
+				zap.String("event_id", eventID),
+				zap.Error(err))
+		}
+		return
+	}
+
+	if err := msg.Ack(); err != nil {
+		c.logger.Error("failed to ack message",
+			zap.String("event_id", eventID),
+			zap.Error(err))
+	}
+}
+
+func (c *Consumer) Close() error {
+	c.closed.Store(true)
+	c.cancel()
+	return c.conn.Drain()
+}
+
+func (c *Consumer) IsHealthy() bool {
+	return !c.closed.Load() && c.conn.IsConnected()
+}
@@ -0,0 +1,304 @@
+// Package kafka provides a Kafka implementation of the event transport.
+// Message values use the configured event envelope; the event type
+// is also sent as a header and the answer (or form) ID is the message key,
+// so all events of one answer land on the same partition in order.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/envelope"
+	kafkago "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// TypeHeader carries entity.Event.Type on every message
+	TypeHeader = "event-type"
+
+	// ContentTypeHeader carries the content type of the message value
+	ContentTypeHeader = "content-type"
+
+	DEFAULT_RETRY_DELAY = 5 * time.Second
+
+	// DEFAULT_MAX_DELIVERIES bounds how often an event whose handling failed
+	// is handed to the listener before its offset is committed anyway
+	DEFAULT_MAX_DELIVERIES = 5
+)
+
+type (
+	// Producer publishes events to the output topic
+	Producer struct {
+		writer *kafkago.Writer
+		logger *logger.Logger
+		events config.Events
+		closed atomic.Bool
+	}
+
+	// Consumer reads events from one topic as part of a consumer group
+	Consumer struct {
+		reader *kafkago.Reader
+		logger *logger.Logger
+		ctx    context.Context
+		cancel context.CancelFunc
+		closed atomic.Bool
+	}
+)
+
+// NewProducer creates a producer writing to cfg.Transport.Kafka.OutputTopic
+// Messages are partitioned by key hash, so ordering holds per answer
+func NewProducer(cfg *config.Config, logger *logger.Logger) (*Producer, error) {
+	kcfg := cfg.Transport.Kafka
+	if len(kcfg.Brokers) == 0 || kcfg.OutputTopic == "" {
+		return nil, fmt.Errorf("invalid kafka config: brokers and output topic are required")
+	}
+
+	return &Producer{
+		writer: &kafkago.Writer{
+			Addr:                   kafkago.TCP(kcfg.Brokers...),
+			Topic:                  kcfg.OutputTopic,
+			Balancer:               &kafkago.Hash{},
+			RequiredAcks:           kafkago.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+		logger: logger,
+		events: cfg.Events,
+	}, nil
+}
+
+// Publish encodes payload into an event of type routingKey and writes it
+// keyed by the payload's answer or form ID
+func (p *Producer) Publish(payload any, routingKey string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		p.logger.Error("error encode payload for publish", zap.Error(err))
+		return err
+	}
+
+	event := envelope.New(p.events.Source, routingKey, data)
+
+	body, contentType, err := envelope.Marshal(event, p.events.Mode)
+	if err != nil {
+		p.logger.Error("error encode event for publish",
+			zap.String("event_id", event.ID),
+			zap.Error(err))
+		return err
+	}
+
+	msg := kafkago.Message{
+		Key:   MessageKey(data),
+		Value: body,
+		Headers: []kafkago.Header{
+			{Key: TypeHeader, Value: []byte(event.Type)},
+			{Key: ContentTypeHeader, Value: []byte(contentType)},
+		},
+		Time: event.Timestamp,
+	}
+
+	if err := p.writer.WriteMessages(context.Background(), msg); err != nil {
+		p.logger.Error("error publishing event",
+			zap.String("event_id", event.ID),
+			zap.Error(err))
+		return err
+	}
+
+	p.logger.Info("successfully published event",
+		zap.String("event_id", event.ID))
+
+	return nil
+}
+
+func (p *Producer) Close() error {
+	p.closed.Store(true)
+	return p.writer.Close()
+}
+
+func (p *Producer) IsHealthy() bool {
+	return !p.closed.Load()
+}
+
+// MessageKey picks the partition key from an event payload:
+// the answer ID when present, otherwise the form ID, otherwise no key
+func MessageKey(payload []byte) []byte {
+	ids := struct {
+		ID     string `json:"id"`
+		FormID string `json:"form_id"`
+	}{}
+
+	if err := json.Unmarshal(payload, &ids); err != nil {
+		return nil
+	}
+
+	switch {
+	case ids.ID != "":
+		return []byte(ids.ID)
+	case ids.FormID != "":
+		return []byte(ids.FormID)
+	default:
+		return nil
+	}
+}
+
+// NewConsumer creates a consumer group member reading cfg.Transport.Kafka.RequestTopic
+func NewConsumer(cfg *config.Config, logger *logger.Logger) (*Consumer, error) {
+	return NewTopicConsumer(cfg, logger, cfg.Transport.Kafka.RequestTopic)
+}
+
+// NewTopicConsumer creates a consumer group member reading topic,
+// e.g. cfg.Transport.Kafka.FormTopic for form service events
+func NewTopicConsumer(cfg *config.Config, logger *logger.Logger, topic string) (*Consumer, error) {
+	kcfg := cfg.Transport.Kafka
+	if len(kcfg.Brokers) == 0 || topic == "" || kcfg.GroupID == "" {
+		return nil, fmt.Errorf("invalid kafka config: brokers, topic and group id are required")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Consumer{
+		reader: kafkago.NewReader(kafkago.ReaderConfig{
+			Brokers: kcfg.Brokers,
+			Topic:   topic,
+			GroupID: kcfg.GroupID,
+		}),
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// ConsumeMessages reads messages until the consumer is closed
+// An offset is committed only after the listener handled its event (see
+// processMessage), so events are redelivered to the group if the service
+// stops mid-way. Malformed messages are committed and skipped.
+func (c *Consumer) ConsumeMessages(outputChan chan entity.Event) {
+	if outputChan == nil {
+		c.logger.Error("output channel cannot be nil")
+		return
+	}
+
+	c.logger.Info("successfully connected to Kafka, waiting for messages...")
+
+	for {
+		msg, err := c.reader.FetchMessage(c.ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || c.closed.Load() {
+				return
+			}
+
+			c.logger.Error("failed to fetch message", zap.Error(err))
+			time.Sleep(DEFAULT_RETRY_DELAY)
+			continue
+		}
+
+		if err := c.processMessage(msg, outputChan); err != nil {
+			if c.ctx.Err() != nil {
+				// Left uncommitted for the next member of the group
+				return
+			}
+
+			c.logger.Error("failed to process message",
+				zap.String("topic", msg.Topic),
+				zap.Int("partition", msg.Partition),
+				zap.Int64("offset", msg.Offset),
+				zap.Error(err))
+		}
+
+		if err := c.reader.CommitMessages(c.ctx, msg); err != nil && !errors.Is(err, context.Canceled) {
+			c.logger.Error("failed to commit message",
+				zap.Int64("offset", msg.Offset),
+				zap.Error(err))
+		}
+	}
+}
+
+// processMessage decodes a message into an event and hands it to the
+// listener until it was handled, at most DEFAULT_MAX_DELIVERIES times.
+// Kafka commits offsets rather than single messages, so the partition
+// waits for the outcome before its next message is read.
+func (c *Consumer) processMessage(msg kafkago.Message, outputChan chan entity.Event) error {
+	event, err := envelope.Unmarshal(msg.Value)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	if event.Type == "" {
+		event.Type = headerValue(msg.Headers, TypeHeader)
+	}
+
+	c.logger.Debug("received new event",
+		zap.String("event_id", event.ID),
+		zap.String("routing_key", event.Type),
+		zap.Time("timestamp", event.Timestamp))
+
+	for delivery := 1; ; delivery++ {
+		requeue, err := c.deliver(*event, outputChan)
+		if err != nil {
+			return err
+		}
+		if !requeue {
+			return nil
+		}
+
+		if delivery == DEFAULT_MAX_DELIVERIES {
+			return fmt.Errorf("event %s still failing after %d deliveries, dropping it", event.ID, delivery)
+		}
+
+		c.logger.Warn("handling event failed, redelivering",
+			zap.String("event_id", event.ID),
+			zap.Int("delivery", delivery))
+
+		select {
+		case <-time.After(DEFAULT_RETRY_DELAY):
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		}
+	}
+}
+
+// deliver sends event on and waits until the listener settled it
+func (c *Consumer) deliver(event entity.Event, outputChan chan entity.Event) (bool, error) {
+	settled := make(chan bool, 1)
+	event.Ack = func(requeue bool) {
+		settled <- requeue
+	}
+
+	select {
+	case outputChan <- event:
+	case <-c.ctx.Done():
+		return false, c.ctx.Err()
+	}
+
+	select {
+	case requeue := <-settled:
+		return requeue, nil
+	case <-c.ctx.Done():
+		return false, c.ctx.Err()
+	}
+}
+
+func (c *Consumer) Close() error {
+	c.closed.Store(true)
+	c.cancel()
+	return c.reader.Close()
+}
+
+func (c *Consumer) IsHealthy() bool {
+	return !c.closed.Load()
+}
+
+func headerValue(headers []kafkago.Header, key string) string {
+	for _, header := range headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
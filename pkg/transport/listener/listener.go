@@ -2,10 +2,13 @@ package listener
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/Koyo-os/answer-service/internal/entity"
-	"github.com/Koyo-os/answer-service/internal/service"
+	"github.com/Koyo-os/answer-service/internal/schema"
 	"github.com/Koyo-os/answer-service/pkg/logger"
 	"github.com/bytedance/sonic"
 	"go.uber.org/zap"
@@ -15,39 +18,78 @@ const (
 	// Event types for answer operations
 	EventTypeAnswerCreate = "request.answer.create"
 	EventTypeAnswerDelete = "request.answer.delete"
+	EventTypeAnswerGet    = "request.answer.get"
+
+	// Event types published by the form service
+	EventTypeFormCreated = "form.created"
+	EventTypeFormUpdated = "form.updated"
+	EventTypeFormDeleted = "form.deleted"
+
+	// RequestPrefix starts the types of events sent by requesters, which
+	// are told about rejections; form service events are not
+	RequestPrefix = "request."
 
 	// Channel buffer size for events
 	DefaultEventChannelSize = 100
 )
 
+// Service is the part of the service layer the listener delegates to.
+// *service.Service implements it; tests can substitute their own.
+type Service interface {
+	Add(*entity.Answer) error
+	Delete(string) error
+	Get(string) (*entity.Answer, error)
+	Reject(*entity.Rejection) error
+	SaveForm(*entity.Form) error
+	DeleteForm(string) error
+}
+
+// Replier sends replies to requests that name a reply destination.
+// The AMQP publisher implements it.
+type Replier interface {
+	Reply(replyTo, correlationID string, reply any) error
+}
+
 // Listener handles incoming events and processes them accordingly.
 // It acts as an event-driven processor for answer-related operations.
 type Listener struct {
-	logger  *logger.Logger
-	service *service.Service
-	events  chan entity.Event
+	logger    *logger.Logger
+	service   Service
+	events    chan entity.Event
+	registry  *schema.Registry
+	validator *schema.Validator
+	replier   Replier
 }
 
 // NewListener creates a new Listener instance with the provided dependencies.
 // It initializes the event channel with a default buffer size to prevent blocking.
-func NewListener(logger *logger.Logger, service *service.Service, events chan entity.Event) *Listener {
+func NewListener(logger *logger.Logger, service Service, events chan entity.Event) *Listener {
 	return &Listener{
-		logger:  logger,
-		service: service,
-		events:  events,
+		logger:    logger,
+		service:   service,
+		events:    events,
+		registry:  DefaultRegistry(),
+		validator: schema.MustNewValidator(),
 	}
 }
 
 // NewListenerWithChannelSize creates a new Listener with a custom event channel buffer size.
 // This allows for fine-tuning the event processing capacity based on expected load.
-func NewListenerWithChannelSize(logger *logger.Logger, service *service.Service, channelSize int) *Listener {
+func NewListenerWithChannelSize(logger *logger.Logger, service Service, channelSize int) *Listener {
 	return &Listener{
-		logger:  logger,
-		service: service,
-		events:  make(chan entity.Event, channelSize),
+		logger:    logger,
+		service:   service,
+		events:    make(chan entity.Event, channelSize),
+		registry:  DefaultRegistry(),
+		validator: schema.MustNewValidator(),
 	}
 }
 
+// SetReplier enables replies to requests carrying a reply destination
+func (l *Listener) SetReplier(replier Replier) {
+	l.replier = replier
+}
+
 // SendEvent sends an event to the listener's event channel.
 // It returns an error if the channel is full to prevent blocking.
 func (l *Listener) SendEvent(event entity.Event) error {
@@ -82,35 +124,89 @@ func (l *Listener) Run(ctx context.Context) {
 
 // processEvent handles individual event processing based on event type.
 // It delegates to specific handler methods for better code organization.
+// Requests that carry a ReplyTo get the handler's outcome as a reply.
+// The event is settled last: requeued after a transient failure,
+// acknowledged otherwise.
 func (l *Listener) processEvent(ctx context.Context, event entity.Event) {
 	l.logger.Debug("processing event",
 		zap.String("event_id", event.ID),
 		zap.String("event_type", event.Type))
 
+	reply := l.handleEvent(&event)
+	if reply != nil {
+		l.sendReply(&event, reply)
+	}
+
+	event.Settle(reply.Retryable())
+}
+
+// handleEvent upcasts and validates the event, then runs the handler of its type
+// Returns the reply for the requester (form service events get one only
+// on failure, which is never sent), or nil for unknown event types
+func (l *Listener) handleEvent(event *entity.Event) *entity.Reply {
+	// Bring payloads of older schema versions to the current shape
+	if err := l.registry.Upcast(event); err != nil {
+		l.logger.Error("failed to upcast event payload",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+			zap.Int("schema_version", event.SchemaVersion),
+			zap.Error(err))
+		return entity.NewErrorReply(event, entity.ReplyCodeInvalidPayload, err.Error())
+	}
+
+	// Refuse payloads that don't match the schema of their type
+	if err := l.validator.Validate(event.Type, event.Payload); err != nil {
+		l.logger.Error("invalid event payload",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+			zap.Error(err))
+
+		var verr *schema.ValidationError
+		if !errors.As(err, &verr) {
+			return entity.NewErrorReply(event, entity.ReplyCodeInternal, err.Error())
+		}
+
+		if strings.HasPrefix(event.Type, RequestPrefix) {
+			l.reject(event, entity.RejectReasonInvalidPayload, verr.Fields...)
+		}
+		return entity.NewErrorReply(event, entity.ReplyCodeInvalidPayload, err.Error(), verr.Fields...)
+	}
+
 	switch event.Type {
 	case EventTypeAnswerCreate:
-		l.handleAnswerCreate(event)
+		return l.handleAnswerCreate(event)
 	case EventTypeAnswerDelete:
-		l.handleAnswerDelete(event)
+		return l.handleAnswerDelete(event)
+	case EventTypeAnswerGet:
+		return l.handleAnswerGet(event)
+	case EventTypeFormCreated, EventTypeFormUpdated:
+		return l.handleFormSave(event)
+	case EventTypeFormDeleted:
+		return l.handleFormDelete(event)
 	default:
 		l.logger.Warn("unknown event type received",
 			zap.String("event_id", event.ID),
 			zap.String("event_type", event.Type))
+		return nil
 	}
 }
 
 // handleAnswerCreate processes answer creation events.
 // It unmarshals the event payload and delegates to the service layer.
-func (l *Listener) handleAnswerCreate(event entity.Event) {
-	answer := new(entity.Answer)
-
+func (l *Listener) handleAnswerCreate(event *entity.Event) *entity.Reply {
 	// Unmarshal the event payload into an Answer entity
-	if err := sonic.Unmarshal(event.Payload, answer); err != nil {
+	answer, err := decodeAnswer(event.Payload)
+	if err != nil {
 		l.logger.Error("failed to unmarshal answer creation event payload",
 			zap.String("event_id", event.ID),
 			zap.String("event_type", event.Type),
 			zap.Error(err))
-		return
+
+		var rerr *entity.RejectError
+		if errors.As(err, &rerr) {
+			return l.failure(event, err)
+		}
+		return entity.NewErrorReply(event, entity.ReplyCodeInvalidPayload, err.Error())
 	}
 
 	// Validate the unmarshaled answer
@@ -119,7 +215,7 @@ func (l *Listener) handleAnswerCreate(event entity.Event) {
 			zap.String("event_id", event.ID),
 			zap.String("answer_id", answer.ID.String()),
 			zap.Error(err))
-		return
+		return entity.NewErrorReply(event, entity.ReplyCodeInvalidPayload, err.Error())
 	}
 
 	// Process the answer creation through the service layer
@@ -128,20 +224,71 @@ func (l *Listener) handleAnswerCreate(event entity.Event) {
 			zap.String("event_id", event.ID),
 			zap.String("answer_id", answer.ID.String()),
 			zap.Error(err))
-		return
+		return l.failure(event, err)
 	}
 
 	l.logger.Info("successfully processed answer creation event",
 		zap.String("event_id", event.ID),
 		zap.String("answer_id", answer.ID.String()))
+
+	return entity.NewOKReply(event, answer.ID.String(), answer)
+}
+
+// answerPayload is the answer creation payload with its elements left raw,
+// so each element's typed value is parsed on its own
+type answerPayload struct {
+	entity.Answer
+	Elements []json.RawMessage `json:"elements"`
+}
+
+// decodeAnswer decodes an answer creation payload
+// Returns an *entity.RejectError naming the element whose value doesn't
+// parse as its value_type
+func decodeAnswer(payload []byte) (*entity.Answer, error) {
+	in := new(answerPayload)
+	if err := sonic.Unmarshal(payload, in); err != nil {
+		return nil, err
+	}
+
+	answer := &in.Answer
+	answer.Elements = make([]entity.Element, len(in.Elements))
+
+	for i, raw := range in.Elements {
+		err := json.Unmarshal(raw, &answer.Elements[i])
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, entity.ErrInvalidValue) {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+
+		// The value is parsed from content unless one was sent
+		field := "content"
+		if probe := (struct {
+			Value json.RawMessage `json:"value"`
+		}{}); json.Unmarshal(raw, &probe) == nil && len(probe.Value) > 0 && string(probe.Value) != "null" {
+			field = "value"
+		}
+
+		return nil, &entity.RejectError{
+			Reason: entity.RejectReasonInvalidPayload,
+			Fields: []entity.FieldError{{
+				Field:   fmt.Sprintf("elements.%d.%s", i, field),
+				Message: err.Error(),
+			}},
+			Err: err,
+		}
+	}
+
+	return answer, nil
 }
 
 // handleAnswerDelete processes answer deletion events.
 // It unmarshals the event payload and delegates to the service layer.
-func (l *Listener) handleAnswerDelete(event entity.Event) {
+func (l *Listener) handleAnswerDelete(event *entity.Event) *entity.Reply {
 	// Define a struct for the delete request payload
 	req := &struct {
-		ID string `json:"id" validate:"required,uuid"`
+		ID string `json:"id"`
 	}{}
 
 	// Unmarshal the event payload into the delete request struct
@@ -150,14 +297,14 @@ func (l *Listener) handleAnswerDelete(event entity.Event) {
 			zap.String("event_id", event.ID),
 			zap.String("event_type", event.Type),
 			zap.Error(err))
-		return
+		return entity.NewErrorReply(event, entity.ReplyCodeInvalidPayload, err.Error())
 	}
 
 	// Validate the request data
 	if req.ID == "" {
 		l.logger.Error("missing answer ID in deletion event",
 			zap.String("event_id", event.ID))
-		return
+		return entity.NewErrorReply(event, entity.ReplyCodeInvalidID, "missing answer ID")
 	}
 
 	// Process the answer deletion through the service layer
@@ -166,12 +313,102 @@ func (l *Listener) handleAnswerDelete(event entity.Event) {
 			zap.String("event_id", event.ID),
 			zap.String("answer_id", req.ID),
 			zap.Error(err))
-		return
+		return l.failure(event, err)
 	}
 
 	l.logger.Info("successfully processed answer deletion event",
 		zap.String("event_id", event.ID),
 		zap.String("answer_id", req.ID))
+
+	return entity.NewOKReply(event, req.ID, nil)
+}
+
+// handleAnswerGet replies with the stored answer, including the score and
+// per-element grading of quiz answers
+func (l *Listener) handleAnswerGet(event *entity.Event) *entity.Reply {
+	req := &struct {
+		ID string `json:"id"`
+	}{}
+
+	if err := sonic.Unmarshal(event.Payload, req); err != nil {
+		l.logger.Error("failed to unmarshal answer read event payload",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+			zap.Error(err))
+		return entity.NewErrorReply(event, entity.ReplyCodeInvalidPayload, err.Error())
+	}
+
+	answer, err := l.service.Get(req.ID)
+	if err != nil {
+		l.logger.Error("failed to get answer",
+			zap.String("event_id", event.ID),
+			zap.String("answer_id", req.ID),
+			zap.Error(err))
+		return entity.NewErrorReply(event, entity.ReplyCode(err), err.Error())
+	}
+
+	return entity.NewOKReply(event, req.ID, answer)
+}
+
+// handleFormSave stores the replica of a created or updated form
+func (l *Listener) handleFormSave(event *entity.Event) *entity.Reply {
+	form := new(entity.Form)
+
+	if err := sonic.Unmarshal(event.Payload, form); err != nil {
+		l.logger.Error("failed to unmarshal form event payload",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+			zap.Error(err))
+		return entity.NewErrorReply(event, entity.ReplyCodeInvalidPayload, err.Error())
+	}
+
+	// Fall back to the event time for producers that don't send updated_at
+	if form.UpdatedAt.IsZero() {
+		form.UpdatedAt = event.Timestamp
+	}
+
+	if err := l.service.SaveForm(form); err != nil {
+		l.logger.Error("failed to save form",
+			zap.String("event_id", event.ID),
+			zap.String("form_id", form.ID.String()),
+			zap.Error(err))
+		return entity.NewErrorReply(event, entity.ReplyCode(err), err.Error())
+	}
+
+	l.logger.Info("successfully processed form event",
+		zap.String("event_id", event.ID),
+		zap.String("event_type", event.Type),
+		zap.String("form_id", form.ID.String()))
+
+	return nil
+}
+
+// handleFormDelete removes the replica of a deleted form
+func (l *Listener) handleFormDelete(event *entity.Event) *entity.Reply {
+	req := &struct {
+		ID string `json:"id"`
+	}{}
+
+	if err := sonic.Unmarshal(event.Payload, req); err != nil {
+		l.logger.Error("failed to unmarshal form deletion event payload",
+			zap.String("event_id", event.ID),
+			zap.Error(err))
+		return entity.NewErrorReply(event, entity.ReplyCodeInvalidPayload, err.Error())
+	}
+
+	if err := l.service.DeleteForm(req.ID); err != nil {
+		l.logger.Error("failed to delete form",
+			zap.String("event_id", event.ID),
+			zap.String("form_id", req.ID),
+			zap.Error(err))
+		return entity.NewErrorReply(event, entity.ReplyCode(err), err.Error())
+	}
+
+	l.logger.Info("successfully processed form deletion event",
+		zap.String("event_id", event.ID),
+		zap.String("form_id", req.ID))
+
+	return nil
 }
 
 // validateAnswer performs basic validation on the answer entity.
@@ -181,11 +418,53 @@ func (l *Listener) validateAnswer(answer *entity.Answer) error {
 		return fmt.Errorf("answer is nil")
 	}
 
-	if answer.ID.String() == "" {
-		return fmt.Errorf("answer ID is empty")
+	return answer.Validate()
+}
+
+// reject publishes an answer.rejected event for a refused request event
+func (l *Listener) reject(event *entity.Event, reason string, fields ...entity.FieldError) {
+	l.publishRejection(event, entity.NewRejection(event, reason, fields...))
+}
+
+func (l *Listener) publishRejection(event *entity.Event, rejection *entity.Rejection) {
+	if err := l.service.Reject(rejection); err != nil {
+		l.logger.Error("failed to publish rejection",
+			zap.String("event_id", event.ID),
+			zap.String("reason", rejection.Reason),
+			zap.Error(err))
+	}
+}
+
+// failure builds the error reply for a service error; refusals the
+// requester should know about are also published as answer.rejected
+func (l *Listener) failure(event *entity.Event, err error) *entity.Reply {
+	var rerr *entity.RejectError
+	if errors.As(err, &rerr) {
+		rejection := entity.NewRejection(event, rerr.Reason, rerr.Fields...)
+		rejection.MissingQuestions = rerr.Missing
+		l.publishRejection(event, rejection)
+
+		reply := entity.NewErrorReply(event, rerr.Reason, err.Error(), rerr.Fields...)
+		reply.Error.MissingQuestions = rerr.Missing
+		return reply
 	}
 
-	return nil
+	return entity.NewErrorReply(event, entity.ReplyCode(err), err.Error())
+}
+
+// sendReply answers the requester if the event asked for a reply and a
+// replier is configured
+func (l *Listener) sendReply(event *entity.Event, reply *entity.Reply) {
+	if event.ReplyTo == "" || l.replier == nil {
+		return
+	}
+
+	if err := l.replier.Reply(event.ReplyTo, event.CorrelationID, reply); err != nil {
+		l.logger.Error("failed to send reply",
+			zap.String("event_id", event.ID),
+			zap.String("reply_to", event.ReplyTo),
+			zap.Error(err))
+	}
 }
 
 // GetEventChannelLength returns the current number of events in the channel.
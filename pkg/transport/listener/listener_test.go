@@ -0,0 +1,453 @@
+package listener_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/listener"
+	"github.com/google/uuid"
+)
+
+// fakeService records what the listener asks of the service layer
+type fakeService struct {
+	mu         sync.Mutex
+	added      []*entity.Answer
+	deleted    []string
+	rejections []*entity.Rejection
+	forms      []*entity.Form
+	formsGone  []string
+	answers    map[string]*entity.Answer
+	err        error // returned by every write instead of recording it
+}
+
+func (s *fakeService) Add(answer *entity.Answer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.added = append(s.added, answer)
+	return nil
+}
+
+func (s *fakeService) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func (s *fakeService) Get(id string) (*entity.Answer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	answer, ok := s.answers[id]
+	if !ok {
+		return nil, entity.ErrAnswerNotFound
+	}
+	return answer, nil
+}
+
+func (s *fakeService) Reject(rejection *entity.Rejection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rejections = append(s.rejections, rejection)
+	return nil
+}
+
+func (s *fakeService) SaveForm(form *entity.Form) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.forms = append(s.forms, form)
+	return nil
+}
+
+func (s *fakeService) DeleteForm(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.formsGone = append(s.formsGone, id)
+	return nil
+}
+
+// fakeReplier records the replies sent to requesters
+type fakeReplier struct {
+	mu      sync.Mutex
+	replies []*entity.Reply
+}
+
+func (r *fakeReplier) Reply(_, _ string, reply any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.replies = append(r.replies, reply.(*entity.Reply))
+	return nil
+}
+
+type fixture struct {
+	events  chan entity.Event
+	service *fakeService
+	replier *fakeReplier
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	f := &fixture{
+		events:  make(chan entity.Event, 1),
+		service: new(fakeService),
+		replier: new(fakeReplier),
+	}
+
+	l := listener.NewListener(logger.Get(), f.service, f.events)
+	l.SetReplier(f.replier)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go l.Run(ctx)
+
+	return f
+}
+
+// handle sends an event of type eventType through the listener and
+// returns the requeue flag it was settled with
+func (f *fixture) handle(t *testing.T, eventType string, payload any) bool {
+	t.Helper()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+
+	event := entity.NewEvent(eventType, data)
+	event.ReplyTo = "replies"
+
+	settled := make(chan bool, 1)
+	event.Ack = func(requeue bool) {
+		settled <- requeue
+	}
+
+	f.events <- *event
+
+	select {
+	case requeue := <-settled:
+		return requeue
+	case <-time.After(5 * time.Second):
+		t.Fatalf("event %s was never settled", eventType)
+		return false
+	}
+}
+
+// lastReply returns the last reply sent
+func (f *fixture) lastReply(t *testing.T) *entity.Reply {
+	t.Helper()
+
+	f.replier.mu.Lock()
+	defer f.replier.mu.Unlock()
+
+	if len(f.replier.replies) == 0 {
+		t.Fatalf("no reply was sent")
+	}
+	return f.replier.replies[len(f.replier.replies)-1]
+}
+
+func answerPayload(elements ...map[string]any) map[string]any {
+	return map[string]any{
+		"id":          uuid.NewString(),
+		"form_id":     uuid.NewString(),
+		"user_id":     uuid.NewString(),
+		"is_complete": true,
+		"elements":    elements,
+	}
+}
+
+func TestRouting(t *testing.T) {
+	answerID, formID := uuid.NewString(), uuid.NewString()
+	form := map[string]any{
+		"id":        formID,
+		"questions": []map[string]any{{"order_number": 1, "type": "text"}},
+	}
+
+	tests := []struct {
+		name      string
+		eventType string
+		payload   any
+		check     func(*fakeService) bool
+	}{
+		{
+			name:      "answer create",
+			eventType: listener.EventTypeAnswerCreate,
+			payload:   answerPayload(map[string]any{"question_order_number": 1, "value_type": "string", "content": "hi"}),
+			check:     func(s *fakeService) bool { return len(s.added) == 1 },
+		},
+		{
+			name:      "answer delete",
+			eventType: listener.EventTypeAnswerDelete,
+			payload:   map[string]any{"id": answerID},
+			check:     func(s *fakeService) bool { return len(s.deleted) == 1 && s.deleted[0] == answerID },
+		},
+		{
+			name:      "form created",
+			eventType: listener.EventTypeFormCreated,
+			payload:   form,
+			check:     func(s *fakeService) bool { return len(s.forms) == 1 && s.forms[0].ID.String() == formID },
+		},
+		{
+			name:      "form updated",
+			eventType: listener.EventTypeFormUpdated,
+			payload:   form,
+			check:     func(s *fakeService) bool { return len(s.forms) == 1 && !s.forms[0].UpdatedAt.IsZero() },
+		},
+		{
+			name:      "form deleted",
+			eventType: listener.EventTypeFormDeleted,
+			payload:   map[string]any{"id": formID},
+			check:     func(s *fakeService) bool { return len(s.formsGone) == 1 && s.formsGone[0] == formID },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			if requeue := f.handle(t, tt.eventType, tt.payload); requeue {
+				t.Fatalf("settled with requeue, want ack")
+			}
+
+			f.service.mu.Lock()
+			defer f.service.mu.Unlock()
+
+			if !tt.check(f.service) {
+				t.Fatalf("service = %+v, want the %s handled", f.service, tt.eventType)
+			}
+			if len(f.service.rejections) != 0 {
+				t.Fatalf("published rejections %+v, want none", f.service.rejections)
+			}
+		})
+	}
+}
+
+func TestInvalidPayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   any
+		rejected  bool
+	}{
+		{
+			name:      "request",
+			eventType: listener.EventTypeAnswerCreate,
+			payload:   map[string]any{"user_id": uuid.NewString(), "elements": []any{}},
+			rejected:  true,
+		},
+		{
+			// Form service events aren't requests, so nobody is told
+			name:      "form event",
+			eventType: listener.EventTypeFormCreated,
+			payload:   map[string]any{"id": "not-a-uuid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			if requeue := f.handle(t, tt.eventType, tt.payload); requeue {
+				t.Fatalf("settled with requeue, want ack")
+			}
+
+			if len(f.service.added) != 0 || len(f.service.forms) != 0 {
+				t.Fatalf("invalid payload reached the service")
+			}
+
+			if !tt.rejected {
+				if len(f.service.rejections) != 0 {
+					t.Fatalf("published rejections %+v, want none", f.service.rejections)
+				}
+				return
+			}
+
+			if len(f.service.rejections) != 1 || f.service.rejections[0].Reason != entity.RejectReasonInvalidPayload {
+				t.Fatalf("rejections = %+v, want one invalid_payload", f.service.rejections)
+			}
+			reply := f.lastReply(t)
+			if reply.Error == nil || reply.Error.Code != entity.ReplyCodeInvalidPayload || len(reply.Error.Fields) == 0 {
+				t.Fatalf("reply = %+v, want invalid_payload error naming the fields", reply)
+			}
+		})
+	}
+}
+
+func TestSettleRequeue(t *testing.T) {
+	text := map[string]any{"question_order_number": 1, "value_type": "string", "content": "hi"}
+
+	tests := []struct {
+		name      string
+		eventType string
+		payload   any
+		err       error
+		requeue   bool
+	}{
+		{
+			name:      "transient failure",
+			eventType: listener.EventTypeAnswerCreate,
+			payload:   answerPayload(text),
+			err:       errors.New("database is down"),
+			requeue:   true,
+		},
+		{
+			name:      "rejected answer",
+			eventType: listener.EventTypeAnswerCreate,
+			payload:   answerPayload(text),
+			err:       &entity.RejectError{Reason: entity.RejectReasonFormNotFound},
+		},
+		{
+			name:      "unknown answer",
+			eventType: listener.EventTypeAnswerDelete,
+			payload:   map[string]any{"id": uuid.NewString()},
+			err:       entity.ErrAnswerNotFound,
+		},
+		{
+			name:      "form save failure",
+			eventType: listener.EventTypeFormDeleted,
+			payload:   map[string]any{"id": uuid.NewString()},
+			err:       errors.New("database is down"),
+			requeue:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.service.err = tt.err
+
+			if requeue := f.handle(t, tt.eventType, tt.payload); requeue != tt.requeue {
+				t.Fatalf("settled with requeue %v, want %v", requeue, tt.requeue)
+			}
+		})
+	}
+}
+
+func TestAnswerCreateRejectsUnparsableTypedValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		element map[string]any
+		field   string
+	}{
+		{
+			name:    "content",
+			element: map[string]any{"question_order_number": 1, "value_type": "number", "content": "four"},
+			field:   "elements.1.content",
+		},
+		{
+			name:    "value",
+			element: map[string]any{"question_order_number": 1, "value_type": "date", "value": "yesterday"},
+			field:   "elements.1.value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			valid := map[string]any{"question_order_number": 2, "value_type": "number", "content": "4.5"}
+			if requeue := f.handle(t, listener.EventTypeAnswerCreate, answerPayload(valid, tt.element)); requeue {
+				t.Fatalf("settled with requeue, want ack")
+			}
+
+			if len(f.service.added) != 0 {
+				t.Fatalf("answer reached the service")
+			}
+			if len(f.service.rejections) != 1 {
+				t.Fatalf("published %d rejections, want 1", len(f.service.rejections))
+			}
+
+			rejection := f.service.rejections[0]
+			if rejection.Reason != entity.RejectReasonInvalidPayload || len(rejection.Errors) != 1 || rejection.Errors[0].Field != tt.field {
+				t.Fatalf("rejection = %+v, want invalid_payload on %s", rejection, tt.field)
+			}
+
+			reply := f.lastReply(t)
+			if reply.Error == nil || reply.Error.Code != entity.ReplyCodeInvalidPayload {
+				t.Fatalf("reply = %+v, want invalid_payload error", reply)
+			}
+		})
+	}
+}
+
+func TestAnswerCreateDecodesTypedValues(t *testing.T) {
+	f := newFixture(t)
+
+	payload := answerPayload(
+		map[string]any{"question_order_number": 1, "value_type": "number", "content": "4.5"},
+		map[string]any{"question_order_number": 2, "value_type": "list", "value": []string{"a"}},
+	)
+	if requeue := f.handle(t, listener.EventTypeAnswerCreate, payload); requeue {
+		t.Fatalf("settled with requeue, want ack")
+	}
+
+	if len(f.service.added) != 1 {
+		t.Fatalf("added %d answers, want 1", len(f.service.added))
+	}
+
+	elements := f.service.added[0].Elements
+	if len(elements) != 2 || elements[0].Value() != 4.5 || elements[1].Content != `["a"]` {
+		t.Fatalf("elements = %+v, want a number and a list", elements)
+	}
+}
+
+func TestAnswerGet(t *testing.T) {
+	f := newFixture(t)
+
+	score, maxScore, points, correct := 2.0, 3.0, 2.0, true
+	stored := &entity.Answer{
+		ID:       uuid.New(),
+		FormID:   uuid.New(),
+		UserID:   uuid.New(),
+		Score:    &score,
+		MaxScore: &maxScore,
+		Elements: []entity.Element{{QuestionOrderNumber: 1, Content: "paris", Correct: &correct, Points: &points}},
+	}
+	f.service.answers = map[string]*entity.Answer{stored.ID.String(): stored}
+
+	if requeue := f.handle(t, listener.EventTypeAnswerGet, map[string]any{"id": stored.ID.String()}); requeue {
+		t.Fatalf("settled with requeue, want ack")
+	}
+
+	reply := f.lastReply(t)
+	if reply.Status != entity.ReplyStatusOK || reply.Answer == nil {
+		t.Fatalf("reply = %+v, want the answer", reply)
+	}
+	if reply.Answer.Score == nil || *reply.Answer.Score != score || reply.Answer.MaxScore == nil || *reply.Answer.MaxScore != maxScore {
+		t.Fatalf("reply score = %v / %v, want %v / %v", reply.Answer.Score, reply.Answer.MaxScore, score, maxScore)
+	}
+	if element := reply.Answer.GetElementByQuestionOrder(1); element == nil || element.Correct == nil || element.Points == nil {
+		t.Fatalf("reply element = %+v, want its grading", element)
+	}
+
+	if requeue := f.handle(t, listener.EventTypeAnswerGet, map[string]any{"id": uuid.NewString()}); requeue {
+		t.Fatalf("unknown answer settled with requeue, want ack")
+	}
+	if reply := f.lastReply(t); reply.Error == nil || reply.Error.Code != entity.ReplyCodeNotFound {
+		t.Fatalf("reply = %+v, want not_found error", reply)
+	}
+}
@@ -0,0 +1,60 @@
+package listener
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/internal/schema"
+)
+
+// Current payload versions of the consumed events
+// Bump a version together with registering the upcaster from the previous one
+const (
+	AnswerCreateSchemaVersion = 2
+	AnswerDeleteSchemaVersion = 1
+	AnswerGetSchemaVersion    = 1
+	FormSchemaVersion         = 1
+)
+
+// DefaultRegistry returns the payload versions and upcasters of the events
+// the listener handles. Register upcasters here when a payload shape changes.
+func DefaultRegistry() *schema.Registry {
+	registry := schema.NewRegistry()
+
+	registry.SetCurrent(EventTypeAnswerCreate, AnswerCreateSchemaVersion)
+	registry.SetCurrent(EventTypeAnswerDelete, AnswerDeleteSchemaVersion)
+	registry.SetCurrent(EventTypeAnswerGet, AnswerGetSchemaVersion)
+	registry.SetCurrent(EventTypeFormCreated, FormSchemaVersion)
+	registry.SetCurrent(EventTypeFormUpdated, FormSchemaVersion)
+	registry.SetCurrent(EventTypeFormDeleted, FormSchemaVersion)
+
+	registry.AddUpcaster(EventTypeAnswerCreate, 1, upcastAnswerCreateV1)
+
+	return registry
+}
+
+// upcastAnswerCreateV1 adds the value_type introduced in v2 to every
+// element: v1 elements only carried text content
+func upcastAnswerCreateV1(payload []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	answer := make(map[string]any)
+	if err := decoder.Decode(&answer); err != nil {
+		return nil, err
+	}
+
+	elements, _ := answer["elements"].([]any)
+	for _, raw := range elements {
+		element, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, ok := element["value_type"]; !ok {
+			element["value_type"] = entity.ValueTypeString
+		}
+	}
+
+	return json.Marshal(answer)
+}
@@ -0,0 +1,67 @@
+package listener_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/pkg/transport/listener"
+)
+
+func TestUpcastAnswerCreateV1(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    int
+		elements   string
+		valueTypes []string
+	}{
+		{"v1 text elements", 1, `[{"question_order_number":1,"content":"12"},{"question_order_number":2,"content":"hi"}]`, []string{"string", "string"}},
+		{"v1 keeps a sent type", 1, `[{"question_order_number":1,"content":"12","value_type":"number"}]`, []string{"number"}},
+		{"v1 without elements", 1, `[]`, []string{}},
+		{"v2 untouched", 2, `[{"question_order_number":1,"value":12,"value_type":"number"}]`, []string{"number"}},
+	}
+
+	registry := listener.DefaultRegistry()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := `{"form_id":"f","user_id":"u","is_complete":true,"elements":` + tt.elements + `}`
+			event := &entity.Event{Type: listener.EventTypeAnswerCreate, SchemaVersion: tt.version, Payload: []byte(payload)}
+
+			if err := registry.Upcast(event); err != nil {
+				t.Fatalf("Upcast: %v", err)
+			}
+			if event.SchemaVersion != listener.AnswerCreateSchemaVersion {
+				t.Fatalf("SchemaVersion = %d, want %d", event.SchemaVersion, listener.AnswerCreateSchemaVersion)
+			}
+
+			var got struct {
+				FormID     string `json:"form_id"`
+				IsComplete bool   `json:"is_complete"`
+				Elements   []struct {
+					Content   string          `json:"content"`
+					Value     json.RawMessage `json:"value"`
+					ValueType string          `json:"value_type"`
+				} `json:"elements"`
+			}
+			if err := json.Unmarshal(event.Payload, &got); err != nil {
+				t.Fatalf("upcast payload %s: %v", event.Payload, err)
+			}
+
+			if got.FormID != "f" || !got.IsComplete {
+				t.Fatalf("upcast payload %s lost answer fields", event.Payload)
+			}
+			if len(got.Elements) != len(tt.valueTypes) {
+				t.Fatalf("got %d elements, want %d", len(got.Elements), len(tt.valueTypes))
+			}
+			for i, want := range tt.valueTypes {
+				if got.Elements[i].ValueType != want {
+					t.Errorf("element %d value_type = %q, want %q", i, got.Elements[i].ValueType, want)
+				}
+			}
+			if tt.version == 1 && len(got.Elements) > 0 && got.Elements[0].Content != "12" {
+				t.Errorf("element 0 content = %q, want it kept", got.Elements[0].Content)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package publisher
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/Koyo-os/answer-service/internal/entity"
+)
+
+// Memory is an in-process stand-in for Publisher
+// It wraps payloads into events exactly like Publisher.Publish and keeps
+// them in order instead of sending them to a broker
+type Memory struct {
+	mu     sync.RWMutex
+	events []entity.Event
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+func (m *Memory) IsHealthy() bool {
+	return true
+}
+
+// Publish encodes payload as JSON and records it as an event of type routingKey
+func (m *Memory) Publish(payload any, routingKey string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event := entity.NewEvent(routingKey, data)
+
+	m.mu.Lock()
+	m.events = append(m.events, *event)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Events returns a copy of every published event in publish order
+func (m *Memory) Events() []entity.Event {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]entity.Event, len(m.events))
+	copy(out, m.events)
+
+	return out
+}
+
+// EventsOfType returns the published events with the given type
+func (m *Memory) EventsOfType(eventType string) []entity.Event {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []entity.Event
+	for _, event := range m.events {
+		if event.Type == eventType {
+			out = append(out, event)
+		}
+	}
+
+	return out
+}
+
+// Reset forgets all recorded events
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	m.events = nil
+	m.mu.Unlock()
+}
@@ -8,10 +8,14 @@ import (
 	"github.com/Koyo-os/answer-service/internal/config"
 	"github.com/Koyo-os/answer-service/internal/entity"
 	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/envelope"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
+// ReplyEventType is the event type of replies to request events
+const ReplyEventType = "answer.reply"
+
 // Publisher handles the publication of events to a message broker
 type Publisher struct {
 	conn    *amqp.Connection // Connection to the message broker
@@ -73,10 +77,10 @@ func (p *Publisher) Publish(poll any, routingKey string) error {
 	}
 
 	// Create a new event with the JSON payload
-	event := entity.NewEvent(routingKey, pollJson)
+	event := envelope.New(p.cfg.Events.Source, routingKey, pollJson)
 
-	// Convert the event to JSON
-	eventJson, err := json.Marshal(event)
+	// Encode the event in the configured encoding and content mode
+	body, contentType, headers, err := p.encode(event)
 	if err != nil {
 		p.logger.Error("error encode event for publish",
 			zap.String("event_id", event.ID),
@@ -92,8 +96,9 @@ func (p *Publisher) Publish(poll any, routingKey string) error {
 		false,                     // mandatory
 		false,                     // immediate
 		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        eventJson,
+			ContentType: contentType,
+			Headers:     amqp.Table(headers),
+			Body:        body,
 			Timestamp:   time.Now(),
 		},
 	)
@@ -109,3 +114,70 @@ func (p *Publisher) Publish(poll any, routingKey string) error {
 
 	return nil
 }
+
+// Reply sends reply straight to the replyTo queue through the default exchange,
+// tagged with the correlation ID of the request it answers
+// Parameters:
+//   - replyTo: Queue named in the request's ReplyTo property
+//   - correlationID: CorrelationId of the request
+//   - reply: Reply payload (will be JSON encoded)
+//
+// Returns:
+//   - error: Any error that occurs during publishing
+func (p *Publisher) Reply(replyTo, correlationID string, reply any) error {
+	replyJson, err := json.Marshal(reply)
+	if err != nil {
+		p.logger.Error("error encode reply", zap.Error(err))
+		return err
+	}
+
+	event := envelope.New(p.cfg.Events.Source, ReplyEventType, replyJson)
+
+	body, contentType, headers, err := p.encode(event)
+	if err != nil {
+		p.logger.Error("error encode reply event",
+			zap.String("event_id", event.ID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	err = p.channel.Publish(
+		"",      // default exchange routes by queue name
+		replyTo, // routing key
+		false,   // mandatory
+		false,   // immediate
+		amqp.Publishing{
+			ContentType:   contentType,
+			Headers:       amqp.Table(headers),
+			CorrelationId: correlationID,
+			Body:          body,
+			Timestamp:     time.Now(),
+		},
+	)
+	if err != nil {
+		p.logger.Error("error publishing reply",
+			zap.String("reply_to", replyTo),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	p.logger.Debug("successfully published reply",
+		zap.String("reply_to", replyTo),
+		zap.String("correlation_id", correlationID),
+	)
+
+	return nil
+}
+
+// encode serializes event as configured: a protobuf answer.v1.Event when
+// the encoding is protobuf, otherwise JSON in the configured content mode
+func (p *Publisher) encode(event *entity.Event) ([]byte, string, map[string]any, error) {
+	if p.cfg.Events.Encoding == envelope.EncodingProtobuf {
+		body, err := envelope.MarshalProtobuf(event)
+		return body, envelope.ContentTypeProtobuf, nil, err
+	}
+
+	return envelope.ToAMQP(event, p.cfg.Events.Mode)
+}
@@ -0,0 +1,342 @@
+// Package redisstream provides a Redis Streams implementation of the event
+// transport, for small deployments that already run Redis for the casher.
+// Each stream entry carries the encoded event envelope in its "event" field,
+// the event type in its "type" field and the envelope content type in its
+// "content_type" field.
+package redisstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Koyo-os/answer-service/internal/config"
+	"github.com/Koyo-os/answer-service/internal/entity"
+	"github.com/Koyo-os/answer-service/pkg/logger"
+	"github.com/Koyo-os/answer-service/pkg/transport/envelope"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	EventField       = "event"
+	TypeField        = "type"
+	ContentTypeField = "content_type"
+
+	DEFAULT_RETRY_DELAY = 5 * time.Second
+
+	// DEFAULT_MAX_DELIVERIES bounds reclaims when the config sets no MaxDeliveries
+	DEFAULT_MAX_DELIVERIES = 5
+)
+
+type (
+	// Publisher appends events to the output stream
+	Publisher struct {
+		client *redis.Client
+		logger *logger.Logger
+		events config.Events
+		stream string
+		maxLen int64
+	}
+
+	// Consumer reads events from one stream as a member of a consumer group
+	Consumer struct {
+		client *redis.Client
+		logger *logger.Logger
+		cfg    config.RedisStreams
+		stream string
+		ctx    context.Context
+		cancel context.CancelFunc
+		closed atomic.Bool
+	}
+)
+
+func NewPublisher(cfg *config.Config, logger *logger.Logger, client *redis.Client) *Publisher {
+	return &Publisher{
+		client: client,
+		logger: logger,
+		events: cfg.Events,
+		stream: cfg.Transport.RedisStreams.OutputStream,
+		maxLen: cfg.Transport.RedisStreams.MaxLen,
+	}
+}
+
+// Publish encodes payload into an event of type routingKey and appends it
+// to the output stream, trimming the stream to roughly MaxLen entries
+func (p *Publisher) Publish(payload any, routingKey string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		p.logger.Error("error encode payload for publish", zap.Error(err))
+		return err
+	}
+
+	event := envelope.New(p.events.Source, routingKey, data)
+
+	body, contentType, err := envelope.Marshal(event, p.events.Mode)
+	if err != nil {
+		p.logger.Error("error encode event for publish",
+			zap.String("event_id", event.ID),
+			zap.Error(err))
+		return err
+	}
+
+	err = p.client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: p.stream,
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: map[string]any{
+			EventField:       body,
+			TypeField:        event.Type,
+			ContentTypeField: contentType,
+		},
+	}).Err()
+	if err != nil {
+		p.logger.Error("error publishing event",
+			zap.String("event_id", event.ID),
+			zap.String("stream", p.stream),
+			zap.Error(err))
+		return err
+	}
+
+	p.logger.Info("successfully published event",
+		zap.String("event_id", event.ID))
+
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return p.client.Close()
+}
+
+func (p *Publisher) IsHealthy() bool {
+	return p.client.Ping(context.Background()).Err() == nil
+}
+
+// NewConsumer joins the consumer group on the request stream
+func NewConsumer(cfg *config.Config, logger *logger.Logger, client *redis.Client) (*Consumer, error) {
+	return NewStreamConsumer(cfg, logger, client, cfg.Transport.RedisStreams.RequestStream)
+}
+
+// NewStreamConsumer joins the consumer group on stream, creating the stream
+// and group if needed
+func NewStreamConsumer(cfg *config.Config, logger *logger.Logger, client *redis.Client, stream string) (*Consumer, error) {
+	rcfg := cfg.Transport.RedisStreams
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := client.XGroupCreateMkStream(ctx, stream, rcfg.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		cancel()
+		return nil, fmt.Errorf("failed to create consumer group %s: %w", rcfg.Group, err)
+	}
+
+	return &Consumer{
+		client: client,
+		logger: logger,
+		cfg:    rcfg,
+		stream: stream,
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// ConsumeMessages reads entries until the consumer is closed
+// Before every read it reclaims entries left pending for longer than
+// ClaimIdle. Entries are handed over one at a time and acknowledged once
+// their event was handled, so nothing this consumer still holds is pending
+// when it reclaims.
+func (c *Consumer) ConsumeMessages(outputChan chan entity.Event) {
+	if outputChan == nil {
+		c.logger.Error("output channel cannot be nil")
+		return
+	}
+
+	c.logger.Info("successfully connected to Redis Streams, waiting for messages...")
+
+	for !c.closed.Load() {
+		if err := c.reclaim(outputChan); err != nil && !c.closed.Load() {
+			c.logger.Error("failed to reclaim pending entries", zap.Error(err))
+		}
+
+		streams, err := c.client.XReadGroup(c.ctx, &redis.XReadGroupArgs{
+			Group:    c.cfg.Group,
+			Consumer: c.cfg.Consumer,
+			Streams:  []string{c.stream, ">"},
+			Count:    c.cfg.BatchSize,
+			Block:    c.cfg.Block,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || c.closed.Load() {
+				continue
+			}
+
+			c.logger.Error("failed to read from stream", zap.Error(err))
+			time.Sleep(DEFAULT_RETRY_DELAY)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				c.processMessage(msg, outputChan)
+			}
+		}
+	}
+}
+
+// reclaim takes over entries that stayed unacknowledged for ClaimIdle
+// Entries already delivered MaxDeliveries times are acknowledged and dropped
+func (c *Consumer) reclaim(outputChan chan entity.Event) error {
+	start := "0-0"
+
+	maxDeliveries := c.cfg.MaxDeliveries
+	if maxDeliveries <= 0 {
+		maxDeliveries = DEFAULT_MAX_DELIVERIES
+	}
+
+	for {
+		msgs, next, err := c.client.XAutoClaim(c.ctx, &redis.XAutoClaimArgs{
+			Stream:   c.stream,
+			Group:    c.cfg.Group,
+			Consumer: c.cfg.Consumer,
+			MinIdle:  c.cfg.ClaimIdle,
+			Start:    start,
+			Count:    c.cfg.BatchSize,
+		}).Result()
+		if err != nil {
+			return err
+		}
+
+		deliveries, err := c.deliveries(msgs)
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range msgs {
+			// The count includes this claim
+			if deliveries[msg.ID] > maxDeliveries {
+				c.logger.Error("entry still failing, dropping it",
+					zap.String("entry_id", msg.ID),
+					zap.Int64("deliveries", deliveries[msg.ID]-1))
+				c.ack(msg.ID)
+				continue
+			}
+
+			c.logger.Warn("reclaimed pending entry",
+				zap.String("entry_id", msg.ID),
+				zap.Int64("delivery", deliveries[msg.ID]))
+
+			c.processMessage(msg, outputChan)
+		}
+
+		if next == "0-0" || len(msgs) == 0 {
+			return nil
+		}
+		start = next
+	}
+}
+
+// deliveries returns how often each claimed entry was delivered, by ID
+func (c *Consumer) deliveries(msgs []redis.XMessage) (map[string]int64, error) {
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+
+	pending, err := c.client.XPendingExt(c.ctx, &redis.XPendingExtArgs{
+		Stream:   c.stream,
+		Group:    c.cfg.Group,
+		Start:    msgs[0].ID,
+		End:      msgs[len(msgs)-1].ID,
+		Count:    int64(len(msgs)),
+		Consumer: c.cfg.Consumer,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(pending))
+	for _, entry := range pending {
+		counts[entry.ID] = entry.RetryCount
+	}
+
+	return counts, nil
+}
+
+// processMessage decodes an entry, hands it off and waits until the listener
+// settled it. The entry is acknowledged once it was handled; an entry whose
+// handling failed stays pending and is reclaimed after ClaimIdle.
+// Malformed entries are acknowledged and dropped so they aren't reclaimed forever
+func (c *Consumer) processMessage(msg redis.XMessage, outputChan chan entity.Event) {
+	event, err := decodeEvent(msg)
+	if err != nil {
+		c.logger.Error("failed to decode entry",
+			zap.String("entry_id", msg.ID),
+			zap.Error(err))
+		c.ack(msg.ID)
+		return
+	}
+
+	c.logger.Debug("received new event",
+		zap.String("event_id", event.ID),
+		zap.String("routing_key", event.Type),
+		zap.Time("timestamp", event.Timestamp))
+
+	settled := make(chan bool, 1)
+	event.Ack = func(requeue bool) {
+		settled <- requeue
+	}
+
+	// On close the entry is left pending; another consumer reclaims it after ClaimIdle
+	select {
+	case outputChan <- *event:
+	case <-c.ctx.Done():
+		return
+	}
+
+	select {
+	case requeue := <-settled:
+		if !requeue {
+			c.ack(msg.ID)
+		}
+	case <-c.ctx.Done():
+	}
+}
+
+func (c *Consumer) ack(id string) {
+	if err := c.client.XAck(c.ctx, c.stream, c.cfg.Group, id).Err(); err != nil {
+		c.logger.Error("failed to ack entry",
+			zap.String("entry_id", id),
+			zap.Error(err))
+	}
+}
+
+func decodeEvent(msg redis.XMessage) (*entity.Event, error) {
+	raw, ok := msg.Values[EventField].(string)
+	if !ok {
+		return nil, fmt.Errorf("entry has no %q field", EventField)
+	}
+
+	event, err := envelope.Unmarshal([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Type == "" {
+		event.Type, _ = msg.Values[TypeField].(string)
+	}
+
+	return event, nil
+}
+
+func (c *Consumer) Close() error {
+	c.closed.Store(true)
+	c.cancel()
+	return c.client.Close()
+}
+
+func (c *Consumer) IsHealthy() bool {
+	return !c.closed.Load() && c.client.Ping(context.Background()).Err() == nil
+}
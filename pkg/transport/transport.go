@@ -0,0 +1,29 @@
+// Package transport defines the broker-agnostic interfaces the service uses
+// to receive request events and publish result events
+package transport
+
+import "github.com/Koyo-os/answer-service/internal/entity"
+
+const (
+	DriverAMQP   = "amqp"
+	DriverKafka  = "kafka"
+	DriverNATS   = "nats"
+	DriverRedis  = "redis"
+	DriverMemory = "memory"
+)
+
+type (
+	// Consumer delivers incoming events to a channel until it is closed
+	Consumer interface {
+		ConsumeMessages(chan entity.Event)
+		Close() error
+		IsHealthy() bool
+	}
+
+	// Publisher wraps a payload into an event of the given type and sends it
+	Publisher interface {
+		Publish(any, string) error
+		Close() error
+		IsHealthy() bool
+	}
+)